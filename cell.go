@@ -29,6 +29,11 @@ func UnmarshalCell(cellType string, data []byte) (Cell, error) {
 		&AnnotatedValueCell{},
 		&SourceCell{},
 		&ImageCell{},
+		&TableCell{},
+		&ChartCell{},
+		&DiffCell{},
+		&CheckCell{},
+		&InputCell{},
 		&JumpCell{},
 		&CustomCell{},
 	}
@@ -209,6 +214,11 @@ func NewMonospaceCell(vals ...any) *MonospaceCell {
 // ValueCell is a cell with pretty-printed Go values.
 type ValueCell struct {
 	Values []string `json:"values"`
+
+	// Trees are the structured representations of the values, rendered as
+	// collapsible trees. Values is used as a fallback in case Trees don't
+	// match them.
+	Trees []ValueTree `json:"trees,omitempty"`
 }
 
 // Returns "ValueCell". Used for marshaling.
@@ -216,16 +226,20 @@ func (c *ValueCell) Type() string {
 	return "ValueCell"
 }
 
-// Append appends pretty-printed vals to the cell.
+// Append appends pretty-printed vals to the cell. [WithLimit] option can be
+// used at any position to override [DefaultLimits].
 func (c *ValueCell) Append(vals ...any) {
+	vals, limits := splitLimit(vals)
 	for _, v := range vals {
-		c.Values = append(c.Values, pprint(v))
+		c.Values = append(c.Values, pprint(v, limits))
+		c.Trees = append(c.Trees, NewValueTree(v, limits))
 	}
 }
 
 // Erase clears the content of the cell.
 func (c *ValueCell) Erase() {
 	c.Values = []string{}
+	c.Trees = nil
 }
 
 // NewValueCell creates [ValueCell].
@@ -274,9 +288,11 @@ func splitAnnotations(avals []any) []annotatedVal {
 
 // Append appends one or more AnnotatedValues to the cell. annotationsAndVals
 // are converted to annotated values by the rules described in [Devcard.Ann].
+// [WithLimit] option can be used at any position to override [DefaultLimits].
 func (c *AnnotatedValueCell) Append(annotationsAndVals ...any) {
+	annotationsAndVals, limits := splitLimit(annotationsAndVals)
 	for _, av := range splitAnnotations(annotationsAndVals) {
-		c.AnnotatedValues = append(c.AnnotatedValues, AnnotatedValue{av.annotation, pprint(av.val)})
+		c.AnnotatedValues = append(c.AnnotatedValues, AnnotatedValue{av.annotation, pprint(av.val, limits)})
 	}
 }
 
@@ -292,7 +308,7 @@ func NewAnnotatedValueCell(annotationsAndVals ...any) *AnnotatedValueCell {
 	return c
 }
 
-// SourceCell is a cell with source code of a function.
+// SourceCell is a cell with source code of Go declarations.
 type SourceCell struct {
 	Decls []string `json:"decls"`
 }
@@ -0,0 +1,153 @@
+package devcard
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Kinds of charts.
+const (
+	ChartLine      = "line"
+	ChartBar       = "bar"
+	ChartScatter   = "scatter"
+	ChartHistogram = "histogram"
+)
+
+// ChartCell is a cell with a chart. It's rendered by the server as SVG.
+type ChartCell struct {
+	Kind   string        `json:"kind"`
+	Title  string        `json:"title"`
+	XLabel string        `json:"x_label"`
+	YLabel string        `json:"y_label"`
+	Labels []string      `json:"labels,omitempty"`
+	Bins   int           `json:"bins,omitempty"`
+	Series []ChartSeries `json:"series"`
+
+	nextName string
+}
+
+// ChartSeries is a named series of data points.
+//
+// When X is empty, the points are placed at the X coordinates 0, 1, 2...
+// For histograms, Y contains the samples.
+type ChartSeries struct {
+	Name string    `json:"name"`
+	X    []float64 `json:"x,omitempty"`
+	Y    []float64 `json:"y"`
+}
+
+// Returns "ChartCell". Used for marshaling.
+func (c *ChartCell) Type() string {
+	return "ChartCell"
+}
+
+type chartCellOption func(*ChartCell)
+
+// WithChartTitle is an option for chart cells. It sets the title of the chart.
+func WithChartTitle(title string) chartCellOption {
+	return func(c *ChartCell) {
+		c.Title = title
+	}
+}
+
+// WithAxisLabels is an option for chart cells. It sets the labels of X and Y
+// axes.
+func WithAxisLabels(x, y string) chartCellOption {
+	return func(c *ChartCell) {
+		c.XLabel, c.YLabel = x, y
+	}
+}
+
+// WithCategories is an option for [Devcard.BarChart]. It sets the labels of
+// the bars (or groups of bars, when there are several series).
+func WithCategories(labels ...string) chartCellOption {
+	return func(c *ChartCell) {
+		c.Labels = labels
+	}
+}
+
+// WithBins is an option for [Devcard.Histogram]. It sets the number of bins.
+func WithBins(n int) chartCellOption {
+	return func(c *ChartCell) {
+		c.Bins = n
+	}
+}
+
+type chartXY struct {
+	x, y any
+}
+
+// XY pairs X and Y coordinates of a series for [Devcard.LineChart] and
+// [Devcard.Scatter]. xs and ys must be slices of numbers of the same length.
+func XY(xs, ys any) chartXY {
+	return chartXY{xs, ys}
+}
+
+// Append appends series to the chart. Each of vals can be one of the following:
+//
+//   - A slice of numbers, which becomes a series of Y coordinates.
+//   - A pair of slices of X and Y coordinates, created with [XY].
+//   - A string, which becomes the name of the next series.
+//   - An option, such as [WithChartTitle] or [WithAxisLabels].
+func (c *ChartCell) Append(vals ...any) {
+	for _, val := range vals {
+		switch x := val.(type) {
+		case chartCellOption:
+			x(c)
+		case string:
+			c.nextName = x
+		case chartXY:
+			xs, ys := toFloats(x.x), toFloats(x.y)
+			if len(xs) != len(ys) {
+				panic(fmt.Sprintf("chart series must have the same number of X and Y coordinates; got %d and %d", len(xs), len(ys)))
+			}
+			c.appendSeries(ChartSeries{X: xs, Y: ys})
+		default:
+			c.appendSeries(ChartSeries{Y: toFloats(x)})
+		}
+	}
+}
+
+func (c *ChartCell) appendSeries(s ChartSeries) {
+	s.Name = c.nextName
+	c.nextName = ""
+	c.Series = append(c.Series, s)
+}
+
+// Erase clears the content of the cell.
+func (c *ChartCell) Erase() {
+	c.Series = []ChartSeries{}
+}
+
+// NewChartCell creates [ChartCell] of the given kind.
+func NewChartCell(kind string, vals ...any) *ChartCell {
+	c := &ChartCell{Kind: kind, Series: []ChartSeries{}}
+	c.Append(vals...)
+	return c
+}
+
+func toFloats(val any) []float64 {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(fmt.Sprintf("chart data must be a slice of numbers; got %T", val))
+	}
+
+	result := make([]float64, v.Len())
+	for i := range result {
+		e := v.Index(i)
+		for e.Kind() == reflect.Interface || e.Kind() == reflect.Pointer {
+			e = e.Elem()
+		}
+		switch {
+		case e.CanInt():
+			result[i] = float64(e.Int())
+		case e.CanUint():
+			result[i] = float64(e.Uint())
+		case e.CanFloat():
+			result[i] = e.Float()
+		default:
+			panic(fmt.Sprintf("chart data must be a slice of numbers; got %T", val))
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package devcard
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CheckCell is a cell with the result of a check, such as [Devcard.Check] or
+// [Devcard.Equal].
+type CheckCell struct {
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+
+	// Diff is the difference between the compared values of a failed
+	// [Devcard.Equal].
+	Diff []DiffLine `json:"diff,omitempty"`
+}
+
+// Returns "CheckCell". Used for marshaling.
+func (c *CheckCell) Type() string {
+	return "CheckCell"
+}
+
+// Append converts vals to strings and appends them to the check's message.
+func (c *CheckCell) Append(vals ...any) {
+	c.Message += valsToString(vals)
+}
+
+// Erase clears the check's message.
+func (c *CheckCell) Erase() {
+	c.Message = ""
+}
+
+// NewCheckCell creates [CheckCell].
+func NewCheckCell(passed bool, vals ...any) *CheckCell {
+	c := &CheckCell{Passed: passed}
+	c.Append(vals...)
+	return c
+}
+
+// Check appends a [CheckCell] to the bottom of the devcard. The check passes if
+// cond is true. vals are converted to strings and joined together to form the
+// check's message. For example:
+//
+//	c.Check(len(items) == 3, "three items are loaded")
+//
+// Check returns cond. The failed checks are counted on the devcard's page, and
+// make "devcards run" exit with an error.
+//
+// The appended CheckCell is immediately sent to the client.
+func (d *Devcard) Check(cond bool, vals ...any) bool {
+	d.check(NewCheckCell(cond, vals...))
+	return cond
+}
+
+// Equal appends a [CheckCell] to the bottom of the devcard. The check passes if
+// want and got are deeply equal (see [reflect.DeepEqual]). If it fails, the
+// cell shows the difference between the pretty-printed values.
+//
+// vals are converted to strings and joined together to form the check's
+// message. Equal returns the result of the check.
+//
+// The appended CheckCell is immediately sent to the client.
+func (d *Devcard) Equal(want, got any, vals ...any) bool {
+	equal := reflect.DeepEqual(want, got)
+	cell := NewCheckCell(equal, vals...)
+	if !equal {
+		text := func(val any) []string {
+			if s, ok := val.(string); ok {
+				return strings.Split(s, "\n")
+			}
+			return strings.Split(pprint(val, DefaultLimits), "\n")
+		}
+		changed := false
+		for _, line := range diffLines(text(want), text(got)) {
+			cell.Diff = append(cell.Diff, line.export())
+			changed = changed || line.op != diffEqual
+		}
+		if !changed {
+			// The values print the same, e.g. 1 and int64(1), or NaN and NaN,
+			// so the diff wouldn't show anything.
+			cell.Diff = nil
+			if cell.Message != "" {
+				cell.Message += ": "
+			}
+			cell.Message += fmt.Sprintf("want %T, got %T; the values print the same but aren't deeply equal", want, got)
+		}
+	}
+	d.check(cell)
+	return equal
+}
+
+func (d *Devcard) check(cell *CheckCell) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.Cells = append(d.Cells, cell)
+	d.sendLastCell()
+}
@@ -0,0 +1,28 @@
+package devcard
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEqualSamePrinted(t *testing.T) {
+	tests := []struct {
+		want, got any
+		types     string
+	}{
+		{1, int64(1), "want int, got int64"},
+		{math.NaN(), math.NaN(), "want float64, got float64"},
+	}
+	for _, test := range tests {
+		d := &Devcard{Cells: []Cell{}}
+		if d.Equal(test.want, test.got, "value") {
+			t.Errorf("%v and %v are considered equal", test.want, test.got)
+			continue
+		}
+		cell := d.Cells[0].(*CheckCell)
+		if len(cell.Diff) != 0 || !strings.HasPrefix(cell.Message, "value: "+test.types) {
+			t.Errorf("got message %q and diff %v", cell.Message, cell.Diff)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"log"
 	"os"
 
 	"github.com/igorhub/devcard/pkg/server"
@@ -11,6 +13,20 @@ import (
 const version = "v0.12.0"
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			export(os.Args[2:])
+			return
+		case "run":
+			run(os.Args[2:])
+			return
+		case "test":
+			test(os.Args[2:])
+			return
+		}
+	}
+
 	var port int
 	var showVersion bool
 	flag.IntVar(&port, "port", 0, "Port for the devcards server")
@@ -24,3 +40,67 @@ func main() {
 
 	server.Run(port)
 }
+
+func export(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: devcards export [flags] [devcard patterns...]")
+		flags.PrintDefaults()
+	}
+	project := flags.String("project", "", "Project to export (defaults to the project in the current directory)")
+	out := flags.String("out", "devcards-export", "Output directory")
+	verbose := flags.Bool("v", false, "Show the server log")
+	flags.Parse(args)
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	if err := server.Export(*project, *out, flags.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: devcards run [flags] <devcard>")
+		flags.PrintDefaults()
+	}
+	project := flags.String("project", "", "Project of the devcard (defaults to the project in the current directory)")
+	format := flags.String("format", server.FormatMarkdown, "Output format: md, json, or html")
+	verbose := flags.Bool("v", false, "Show the server log")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	if err := server.RunDevcard(os.Stdout, *project, flags.Arg(0), *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func test(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: devcards test [flags] [devcard patterns...]")
+		flags.PrintDefaults()
+	}
+	project := flags.String("project", "", "Project to test (defaults to the project in the current directory)")
+	update := flags.Bool("update", false, "Overwrite the snapshots with the current output of the devcards")
+	verbose := flags.Bool("v", false, "Show the server log")
+	flags.Parse(args)
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	if err := server.TestSnapshots(os.Stdout, *project, flags.Args(), *update); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
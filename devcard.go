@@ -6,6 +6,7 @@ package devcard
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
@@ -24,17 +25,20 @@ type Devcard struct {
 	Cells   []Cell   `json:"cells"`
 	CSS     []string `json:"css,omitempty"`
 
-	lock    sync.RWMutex
-	updates chan string
+	lock      sync.RWMutex
+	updates   chan string
+	params    url.Values
+	locations map[int]location // by the cell's index
 }
 
-func newDevcard(title, tempDir string) *Devcard {
+func newDevcard(title, tempDir string, params url.Values) *Devcard {
 	return &Devcard{
 		Title:   title,
 		TempDir: tempDir,
 		Cells:   []Cell{},
 
 		updates: make(chan string, 4096),
+		params:  params,
 	}
 }
 
@@ -59,23 +63,34 @@ func Debug(producer DevcardProducer) {
 // DevcardMeta describes devcard's metadata.
 type DevcardMeta struct {
 	// ImportPath is the import path of the devcard's package.
-	ImportPath string
+	ImportPath string `json:"import_path"`
 
 	// Package is the name of the package that the devcard belongs to.
-	Package string
+	Package string `json:"package"`
+
+	// ModuleDir is the relative path (from the project dir) to the root of the
+	// module that the devcard belongs to.
+	ModuleDir string `json:"module_dir"`
 
 	// Path is the relative path (from the project dir) to the source file where
 	// the devcard is located.
-	Path string
+	Path string `json:"path"`
 
 	// Line is a line number in the source file where the devcard is located.
-	Line int
+	Line int `json:"line"`
 
 	// Name is the name of the devcard-producing function.
-	Name string
+	Name string `json:"name"`
 
 	// Title is the title of the devcard.
-	Title string
+	Title string `json:"title"`
+
+	// Doc is the doc comment of the devcard-producing function.
+	Doc string `json:"doc"`
+
+	// Test is true if the devcard is located in a _test.go file. Such
+	// devcards are built into the test binary of their package.
+	Test bool `json:"test"`
 }
 
 // Caption returns the devcard's title, or, in case it's empty, the name of
@@ -123,11 +138,14 @@ func (d *Devcard) sendCell(index int) {
 	if customCell, ok := cell.(customCell); ok {
 		cell = customCell.Cast()
 	}
+	loc := d.locate(index)
 	d.send(map[string]any{
 		"msg_type":  MessageTypeCell,
 		"cell_type": cell.Type(),
 		"id":        "b" + strconv.Itoa(index),
 		"cell":      cell,
+		"file":      loc.file,
+		"line":      loc.line,
 	})
 }
 
@@ -273,7 +291,15 @@ func (d *Devcard) MonoFmt(format string, a ...any) *MonospaceCell {
 }
 
 // Val appends a [ValueCell] to the bottom of the devcard. vals are
-// pretty-printed and joined together.
+// pretty-printed and joined together. In the browser, structs, slices, and
+// maps are shown as collapsible trees.
+//
+// The printed values are truncated according to [DefaultLimits]. Use
+// [WithLimit] option at any position to override them:
+//
+//	c.Val(devcard.WithLimit(devcard.Limits{MaxElements: 1000}), hugeSlice)
+//
+// The limits left unset keep their default values.
 //
 // The appended ValueCell is immediately sent to the client.
 func (d *Devcard) Val(vals ...any) *ValueCell {
@@ -293,6 +319,9 @@ func (d *Devcard) Val(vals ...any) *ValueCell {
 //
 //	c.Ann("Loaded config:", cfg, "Default config:", defaultConfig())
 //
+// As in [Devcard.Val], the values are truncated according to [DefaultLimits],
+// unless [WithLimit] option is used.
+//
 // The appended AnnotatedValueCell is immediately sent to the client.
 func (d *Devcard) Ann(annotationsAndVals ...any) *AnnotatedValueCell {
 	d.lock.Lock()
@@ -305,11 +334,15 @@ func (d *Devcard) Ann(annotationsAndVals ...any) *AnnotatedValueCell {
 
 // Source appends a [SourceCell] to the bottom of the devcard.
 //
-// The cell contains the source of the declarations decls. As of now, only
-// function declarations are supported. Declarations must be prefixed with the
-// name of their package. For example:
+// The cell contains the source of the declarations decls, along with their
+// doc comments. Functions, types, methods, constants, and variables are
+// supported. Declarations must be prefixed with the name of their package;
+// methods must also be prefixed with the name of their type. For example:
 //
 //	c.Source("examples.DevcardTextCells")
+//	c.Source("examples.Point", "examples.Point.String")
+//
+// Grouped type, const, and var declarations are shown as a whole.
 //
 // The appended SourceCell is immediately sent to the client.
 func (d *Devcard) Source(decls ...string) *SourceCell {
@@ -347,6 +380,91 @@ func (d *Devcard) Image(annotationsAndImages ...any) *ImageCell {
 	return cell
 }
 
+// Table appends a [TableCell] to the bottom of the devcard. vals are converted
+// into table rows by the rules described in [TableCell.Append].
+//
+// [WithColumnFormat] option can be used to format the values in a column. For
+// example:
+//
+//	c.Table(devcard.WithColumnFormat("Price", "%.2f"), products)
+//
+// The appended TableCell is immediately sent to the client.
+func (d *Devcard) Table(vals ...any) *TableCell {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	cell := NewTableCell(vals...)
+	d.Cells = append(d.Cells, cell)
+	d.sendLastCell()
+	return cell
+}
+
+// Diff appends a [DiffCell] with the line-by-line difference between a and b
+// to the bottom of the devcard. Strings are compared as they are; other values
+// are pretty-printed first. For example:
+//
+//	c.Diff(want, got, devcard.WithDiffLabels("want", "got"))
+//
+// [WithSideBySide] option shows the values side by side, rather than in a
+// unified diff.
+//
+// The appended DiffCell is immediately sent to the client.
+func (d *Devcard) Diff(a, b any, opts ...any) *DiffCell {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	cell := NewDiffCell(append([]any{a, b}, opts...)...)
+	d.Cells = append(d.Cells, cell)
+	d.sendLastCell()
+	return cell
+}
+
+func (d *Devcard) chart(kind string, vals []any) *ChartCell {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	cell := NewChartCell(kind, vals...)
+	d.Cells = append(d.Cells, cell)
+	d.sendLastCell()
+	return cell
+}
+
+// LineChart appends a line chart ([ChartCell]) to the bottom of the devcard.
+// vals are converted into series by the rules described in [ChartCell.Append].
+// For example:
+//
+//	c.LineChart(devcard.WithChartTitle("Growth"), "linear", linear, "quadratic", devcard.XY(xs, squares))
+//
+// The appended ChartCell is immediately sent to the client.
+func (d *Devcard) LineChart(vals ...any) *ChartCell {
+	return d.chart(ChartLine, vals)
+}
+
+// BarChart appends a bar chart ([ChartCell]) to the bottom of the devcard.
+// vals are converted into series by the rules described in [ChartCell.Append].
+// Bars can be labeled with [WithCategories] option. For example:
+//
+//	c.BarChart(devcard.WithCategories("Mon", "Tue", "Wed"), "visits", []int{120, 98, 143})
+//
+// The appended ChartCell is immediately sent to the client.
+func (d *Devcard) BarChart(vals ...any) *ChartCell {
+	return d.chart(ChartBar, vals)
+}
+
+// Scatter appends a scatter plot ([ChartCell]) to the bottom of the devcard.
+// vals are converted into series by the rules described in [ChartCell.Append].
+//
+// The appended ChartCell is immediately sent to the client.
+func (d *Devcard) Scatter(vals ...any) *ChartCell {
+	return d.chart(ChartScatter, vals)
+}
+
+// Histogram appends a histogram ([ChartCell]) to the bottom of the devcard.
+// vals are converted into series of samples by the rules described in
+// [ChartCell.Append]. The number of bins can be set with [WithBins] option.
+//
+// The appended ChartCell is immediately sent to the client.
+func (d *Devcard) Histogram(vals ...any) *ChartCell {
+	return d.chart(ChartHistogram, vals)
+}
+
 // Not documented. Subject to change.
 func (d *Devcard) Jump() *JumpCell {
 	d.lock.Lock()
@@ -366,6 +484,8 @@ func (d *Devcard) Jump() *JumpCell {
 //   - For [ValueCell], same rules as in [Devcard.Val] apply.
 //   - Fro [AnnotatedValueCell], same rules as in [Devcard.Ann] apply.
 //   - For [ImageCell], same rules as in [Devcard.Image] apply.
+//   - For [TableCell], same rules as in [Devcard.Table] apply.
+//   - For [ChartCell], same rules as in [Devcard.LineChart] apply.
 //   - For other types of cells, Append is a noop.
 //
 // The bottom cell is immediately sent to the client.
@@ -433,6 +553,7 @@ func (d *Devcard) Replace(oldCell, newCell Cell) {
 		panic(&cellError{oldCell})
 	}
 	d.Cells[i] = newCell
+	delete(d.locations, i)
 	d.sendCell(i)
 }
 
@@ -447,6 +568,7 @@ func (d *Devcard) ReplaceLast(newCell Cell) {
 		return
 	}
 	d.Cells[i] = newCell
+	delete(d.locations, i)
 	d.sendLastCell()
 }
 
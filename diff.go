@@ -0,0 +1,270 @@
+package devcard
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DiffCell is a cell with the line-by-line difference between two values or
+// texts.
+type DiffCell struct {
+	// Labels of the compared values, such as "want" and "got". They're
+	// optional.
+	LabelA string `json:"label_a,omitempty"`
+	LabelB string `json:"label_b,omitempty"`
+
+	Lines []DiffLine `json:"lines"`
+
+	// SideBySide is true if the values are to be shown side by side, rather
+	// than in a unified diff.
+	SideBySide bool `json:"side_by_side,omitempty"`
+}
+
+// DiffLine is a line of [DiffCell].
+type DiffLine struct {
+	// Op is one of "=" (the line is present in both values), "-" (the line
+	// is present only in the first value), or "+" (the line is present only in
+	// the second value).
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Returns "DiffCell". Used for marshaling.
+func (c *DiffCell) Type() string {
+	return "DiffCell"
+}
+
+type diffCellOption func(*DiffCell)
+
+// WithSideBySide is an option for [Devcard.Diff]. It shows the compared values
+// side by side, rather than in a unified diff.
+func WithSideBySide() diffCellOption {
+	return func(c *DiffCell) {
+		c.SideBySide = true
+	}
+}
+
+// WithDiffLabels is an option for [Devcard.Diff]. It sets the labels of the
+// compared values, such as "want" and "got".
+func WithDiffLabels(a, b string) diffCellOption {
+	return func(c *DiffCell) {
+		c.LabelA, c.LabelB = a, b
+	}
+}
+
+// Append compares two values, and replaces the cell's lines with their
+// difference: the cell holds a single comparison. Strings are compared as they
+// are; other values are pretty-printed first (the same way as in
+// [ValueCell]). A single value is compared against an empty string. Append
+// panics if given more than two values.
+//
+// [WithSideBySide], [WithDiffLabels], and [WithLimit] options can be used at
+// any position.
+func (c *DiffCell) Append(vals ...any) {
+	vals, limits := splitLimit(vals)
+	i := 0
+	for _, val := range vals {
+		if opt, ok := val.(diffCellOption); ok {
+			opt(c)
+		} else {
+			vals[i] = val
+			i++
+		}
+	}
+	vals = vals[:i]
+	if len(vals) > 2 {
+		panic(fmt.Sprintf("diff must compare two values; got %d", len(vals)))
+	}
+	if len(vals) == 0 {
+		return
+	}
+
+	text := func(val any) string {
+		if s, ok := val.(string); ok {
+			return s
+		}
+		return pprint(val, limits)
+	}
+	a, b := text(vals[0]), ""
+	if len(vals) == 2 {
+		b = text(vals[1])
+	}
+	c.Lines = []DiffLine{}
+	for _, line := range diffLines(strings.Split(a, "\n"), strings.Split(b, "\n")) {
+		c.Lines = append(c.Lines, line.export())
+	}
+}
+
+// Erase clears the content of the cell.
+func (c *DiffCell) Erase() {
+	c.Lines = []DiffLine{}
+}
+
+// NewDiffCell creates [DiffCell].
+func NewDiffCell(vals ...any) *DiffCell {
+	c := &DiffCell{Lines: []DiffLine{}}
+	c.Append(vals...)
+	return c
+}
+
+// Kinds of lines in a diff.
+const (
+	diffEqual  = ' '
+	diffDelete = '-'
+	diffInsert = '+'
+)
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// export converts the line into [DiffLine].
+func (l diffLine) export() DiffLine {
+	if l.op == diffEqual {
+		return DiffLine{Op: "=", Text: l.text}
+	}
+	return DiffLine{Op: string(l.op), Text: l.text}
+}
+
+// diffLines computes the line-by-line difference between a and b, using the
+// longest common subsequence of their lines.
+//
+// The common prefix and suffix of a and b are excluded from the search. If the
+// rest is too large for the search, it's reported as fully replaced.
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffLine{diffEqual, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append(suffix, diffLine{diffEqual, a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	slices.Reverse(suffix)
+
+	var result []diffLine
+	if (len(a)+1)*(len(b)+1) > maxDiffArea {
+		for _, line := range a {
+			result = append(result, diffLine{diffDelete, line})
+		}
+		for _, line := range b {
+			result = append(result, diffLine{diffInsert, line})
+		}
+	} else {
+		result = diffLCS(a, b)
+	}
+	return slices.Concat(prefix, result, suffix)
+}
+
+// maxDiffArea limits the size of the table used by diffLCS (4 bytes per
+// entry).
+const maxDiffArea = 1_000_000
+
+func diffLCS(a, b []string) []diffLine {
+	// lcs[i*w+j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	w := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+
+	var result []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, diffLine{diffEqual, a[i]})
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			result = append(result, diffLine{diffDelete, a[i]})
+			i++
+		default:
+			result = append(result, diffLine{diffInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		result = append(result, diffLine{diffDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		result = append(result, diffLine{diffInsert, b[j]})
+	}
+	return result
+}
+
+// unifiedDiff formats the difference between a and b in the manner of
+// "diff -u" (see [formatDiff]). It returns an empty string if a and b are
+// equal.
+func unifiedDiff(a, b string, context int) string {
+	lines := diffLines(strings.Split(a, "\n"), strings.Split(b, "\n"))
+	if !slices.ContainsFunc(lines, func(line diffLine) bool { return line.op != diffEqual }) {
+		return ""
+	}
+	exported := make([]DiffLine, len(lines))
+	for i, line := range lines {
+		exported[i] = line.export()
+	}
+	return formatDiff(exported, context)
+}
+
+// visibleDiffLines reports which lines of the diff are shown: the changed
+// lines and the unchanged lines within context lines from them. If nothing has
+// changed, all lines are shown.
+func visibleDiffLines(lines []DiffLine, context int) []bool {
+	show := make([]bool, len(lines))
+	changed := false
+	for i, line := range lines {
+		if line.Op == "=" {
+			continue
+		}
+		changed = true
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			show[k] = true
+		}
+	}
+	if !changed {
+		for i := range show {
+			show[i] = true
+		}
+	}
+	return show
+}
+
+// formatDiff formats the lines in the manner of "diff -u", leaving out the
+// unchanged lines that are farther than context lines away from the changes.
+// The left out lines are marked with "...".
+func formatDiff(lines []DiffLine, context int) string {
+	s := new(strings.Builder)
+	show := visibleDiffLines(lines, context)
+	skipped := false
+	for i, line := range lines {
+		if !show[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			s.WriteString("  ...\n")
+			skipped = false
+		}
+		op := line.Op
+		if op == "=" {
+			op = " "
+		}
+		s.WriteString(op + " " + line.Text + "\n")
+	}
+	if skipped {
+		s.WriteString("  ...\n")
+	}
+	return s.String()
+}
@@ -0,0 +1,146 @@
+package devcard
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Kinds of inputs.
+const (
+	InputSlider   = "slider"
+	InputCheckbox = "checkbox"
+	InputSelect   = "select"
+	InputText     = "text"
+)
+
+// InputCell is a cell with an input control, such as a slider or a checkbox.
+//
+// When the user changes the value of the control, the devcard is re-run, and
+// the new value is returned by the method that created the cell, such as
+// [Devcard.Slider].
+type InputCell struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Value   string   `json:"value"`
+	Min     int      `json:"min,omitempty"`
+	Max     int      `json:"max,omitempty"`
+	Options []string `json:"options,omitempty"`
+}
+
+// Returns "InputCell". Used for marshaling.
+func (c *InputCell) Type() string {
+	return "InputCell"
+}
+
+// Noop.
+func (c *InputCell) Append(vals ...any) {
+}
+
+// Noop.
+func (c *InputCell) Erase() {
+}
+
+func (d *Devcard) input(cell *InputCell) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.Cells = append(d.Cells, cell)
+	d.sendLastCell()
+}
+
+// param returns the value of the devcard's parameter, and whether it's set.
+func (d *Devcard) param(name string) (string, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if !d.params.Has(name) {
+		return "", false
+	}
+	return d.params.Get(name), true
+}
+
+// Param returns the value of the devcard's parameter. The parameters are
+// taken from the query string of the devcard's URL, so that a variant of the
+// devcard can be bookmarked or shared: for example,
+// /devcards/project/DevcardFoobar?seed=7 sets the parameter "seed" to "7".
+//
+// If the parameter isn't set, value is returned.
+//
+// The inputs' values are parameters too, so the URL's query string can also
+// set the initial values of the devcard's inputs.
+func (d *Devcard) Param(name, value string) string {
+	if s, ok := d.param(name); ok {
+		return s
+	}
+	return value
+}
+
+// Slider appends an [InputCell] with a slider to the bottom of the devcard,
+// and returns the slider's current value.
+//
+// The value is an integer in the range [min, max]. When the devcard is run for
+// the first time, value is returned.
+//
+// The appended InputCell is immediately sent to the client.
+func (d *Devcard) Slider(name string, min, max, value int) int {
+	if s, ok := d.param(name); ok {
+		if n, err := strconv.Atoi(s); err == nil {
+			value = n
+		}
+	}
+	value = clamp(value, min, max)
+	d.input(&InputCell{Kind: InputSlider, Name: name, Value: strconv.Itoa(value), Min: min, Max: max})
+	return value
+}
+
+func clamp(value, lo, hi int) int {
+	if value < lo {
+		return lo
+	}
+	if value > hi {
+		return hi
+	}
+	return value
+}
+
+// Checkbox appends an [InputCell] with a checkbox to the bottom of the
+// devcard, and returns the checkbox's current state.
+//
+// When the devcard is run for the first time, value is returned.
+//
+// The appended InputCell is immediately sent to the client.
+func (d *Devcard) Checkbox(name string, value bool) bool {
+	if s, ok := d.param(name); ok {
+		if b, err := strconv.ParseBool(s); err == nil {
+			value = b
+		}
+	}
+	d.input(&InputCell{Kind: InputCheckbox, Name: name, Value: strconv.FormatBool(value)})
+	return value
+}
+
+// Select appends an [InputCell] with a drop-down list of options to the
+// bottom of the devcard, and returns the currently selected option.
+//
+// When the devcard is run for the first time, value is returned.
+//
+// The appended InputCell is immediately sent to the client.
+func (d *Devcard) Select(name string, options []string, value string) string {
+	if s, ok := d.param(name); ok && slices.Contains(options, s) {
+		value = s
+	}
+	d.input(&InputCell{Kind: InputSelect, Name: name, Value: value, Options: options})
+	return value
+}
+
+// TextInput appends an [InputCell] with a text field to the bottom of the
+// devcard, and returns the field's current content.
+//
+// When the devcard is run for the first time, value is returned.
+//
+// The appended InputCell is immediately sent to the client.
+func (d *Devcard) TextInput(name, value string) string {
+	if s, ok := d.param(name); ok {
+		value = s
+	}
+	d.input(&InputCell{Kind: InputText, Name: name, Value: value})
+	return value
+}
@@ -0,0 +1,58 @@
+package devcard
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// location is the location in the source code where a cell was created.
+type location struct {
+	file string
+	line int
+}
+
+var packagePath = reflect.TypeFor[Devcard]().PkgPath()
+
+// callerLocation returns the location of the code that called into the
+// devcard package, that is, of the innermost stack frame that belongs to
+// neither this package nor the Go runtime.
+func callerLocation() location {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if pkg := funcPackage(frame.Function); pkg != packagePath && pkg != "runtime" && frame.File != "" {
+			return location{frame.File, frame.Line}
+		}
+		if !more {
+			return location{}
+		}
+	}
+}
+
+// funcPackage returns the import path of the package of the function, given
+// its fully qualified name, such as "example.com/pkg.(*Type).Method".
+func funcPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot == -1 {
+		return name
+	}
+	return name[:slash+1+dot]
+}
+
+// locate records the location of the cell at index, unless it's known
+// already.
+func (d *Devcard) locate(index int) location {
+	if d.locations == nil {
+		d.locations = make(map[int]location)
+	}
+	loc, ok := d.locations[index]
+	if !ok {
+		loc = callerLocation()
+		d.locations[index] = loc
+	}
+	return loc
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/igorhub/devcard/pkg/internal/file"
@@ -21,10 +22,26 @@ type Config struct {
 	Err  error
 
 	Port int
+	Bind string
+
+	// AllowedDirs are the directories whose files can be served by the
+	// server, in addition to the files produced by the devcards.
+	AllowedDirs []string `toml:"allowed-dirs"`
 
 	Editor string
+
+	// Opener is the command that opens the file in the editor, overriding
+	// the Editor. It's either an executable called with the file's path and
+	// line as arguments, or a command line with the {file}, {line}, and
+	// {column} placeholders.
 	Opener string `toml:"custom-opener"`
 
+	// NeovimServer is the address of the Neovim server (see nvim --listen).
+	NeovimServer string `toml:"neovim-server"`
+
+	// HelixPane is the tmux pane (target) running Helix.
+	HelixPane string `toml:"helix-tmux-pane"`
+
 	Projects []ProjectConfig
 
 	Appearance struct {
@@ -38,6 +55,28 @@ type ProjectConfig struct {
 	Dir        string
 	Injection  string
 	Generators map[string][]string
+
+	// Timeout is the maximum duration of a devcard's run. Zero means no limit.
+	Timeout time.Duration
+
+	// History is the number of runs kept in the history of each devcard.
+	// Zero means the default.
+	History int
+
+	// RunTests enables running the tests of the devcard's package alongside
+	// the devcard. It's false unless enabled in the config, as the tests run
+	// each time the devcard's page is opened.
+	RunTests bool
+
+	// Ignore lists the patterns (in .gitignore format) of the files that
+	// must be neither synced nor watched, in addition to the ones listed in
+	// the project's .gitignore files.
+	Ignore []string
+
+	// NeverIgnore lists the patterns (in .gitignore format) of the files that
+	// must be synced and watched even though they're ignored by .gitignore
+	// files or Ignore, such as embedded assets or generated code.
+	NeverIgnore []string
 }
 
 func configPath() (string, error) {
@@ -83,14 +122,38 @@ func (cfg *Config) readConfig(path string) {
 	cfg.Err = cfg.readProjects()
 }
 
+// AllowsFile reports whether the file at path is located in one of the
+// allowed dirs.
+func (cfg *Config) AllowsFile(path string) bool {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	for _, dir := range cfg.AllowedDirs {
+		dir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
 // readProjects reads the projects from cfg.Data and puts them in the same order
 // they are described in the config file.
 func (cfg *Config) readProjects() error {
 	var x struct {
 		Project map[string]struct {
-			Dir        string
-			Inject     string              `toml:"inject-code"`
-			Generators map[string][]string `toml:"code-generators"`
+			Dir         string
+			Inject      string              `toml:"inject-code"`
+			Generators  map[string][]string `toml:"code-generators"`
+			Timeout     string
+			History     int
+			Ignore      []string
+			NeverIgnore []string `toml:"never-ignore"`
+			RunTests    bool     `toml:"run-tests"`
 		}
 	}
 	meta, err := toml.Decode(string(cfg.Data), &x)
@@ -104,10 +167,20 @@ func (cfg *Config) readProjects() error {
 
 	for name, p := range x.Project {
 		pc := ProjectConfig{
-			Name:       name,
-			Dir:        p.Dir,
-			Injection:  p.Inject,
-			Generators: p.Generators,
+			Name:        name,
+			Dir:         p.Dir,
+			Injection:   p.Inject,
+			Generators:  p.Generators,
+			History:     p.History,
+			Ignore:      p.Ignore,
+			NeverIgnore: p.NeverIgnore,
+			RunTests:    p.RunTests,
+		}
+		if p.Timeout != "" {
+			pc.Timeout, err = time.ParseDuration(p.Timeout)
+			if err != nil {
+				return fmt.Errorf("project %s: invalid timeout: %w", name, err)
+			}
 		}
 		cfg.Projects = append(cfg.Projects, pc)
 	}
@@ -133,7 +206,13 @@ func (cfg *Config) Create() error {
 	}
 
 	format := `port = %d
+# The address the server listens on; use "0.0.0.0" to make it accessible from other hosts.
+bind = "127.0.0.1"
+# Supported editors: vscode, emacs, neovim, idea, goland, zed, sublime, helix
 editor = "vscode"
+# neovim-server = "/tmp/nvim.sock"  # neovim: the server's address (see nvim --listen)
+# helix-tmux-pane = "editor"  # helix: the tmux pane running hx
+# custom-opener = "my-editor --goto {file}:{line}:{column}"  # overrides the editor
 
 [appearance]
 # Builtin styles:
@@ -148,6 +227,11 @@ code-highlighting = "tango"
 %s
 # [project.name-of-your-project]
 # dir = "/absolute/path/to/your/project"
+# timeout = "30s"  # stop the devcards that run for longer than that
+# history = 10  # the number of runs to keep in the history of each devcard
+# ignore = ["node_modules/", "/data/"]  # skip these files, in addition to .gitignore
+# never-ignore = ["/web/dist/"]  # sync these files even if they're ignored (e.g. embedded assets)
+# run-tests = true  # run the package's tests alongside the devcard, each time its page is opened
 `
 	s := fmt.Sprintf(format, cfg.Port, projectsStr)
 
@@ -176,6 +260,7 @@ func createGenerators(configDir string) {
 func defaultConfig() Config {
 	cfg := Config{
 		Port:   50051,
+		Bind:   "127.0.0.1",
 		Editor: "vscode",
 	}
 	cfg.Appearance.Stylesheets = []string{"builtin", "builtin/light"}
@@ -197,9 +282,19 @@ func defaultConfig() Config {
 	return cfg
 }
 
+// projectRoot returns the dir containing go.work (as the workspace includes
+// all its modules), or, if there's none, the dir containing go.mod.
 func projectRoot(dir string) string {
-	mod := filepath.Join(dir, "go.mod")
-	if _, err := os.Stat(mod); err == nil {
+	if root := findUp(dir, "go.work"); root != "" {
+		return root
+	}
+	return findUp(dir, "go.mod")
+}
+
+// findUp returns the closest dir containing the file with the given name,
+// starting with dir and going up.
+func findUp(dir, name string) string {
+	if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
 		return dir
 	}
 
@@ -207,5 +302,5 @@ func projectRoot(dir string) string {
 	if parent == dir {
 		return ""
 	}
-	return projectRoot(parent)
+	return findUp(parent, name)
 }
@@ -12,9 +12,9 @@ import (
 var hashSeed = maphash.MakeSeed()
 
 func DevcardMainDir(meta devcard.DevcardMeta) string {
-	// If the devcard is located in a main package, our main function must be
-	// placed in the same director.
-	if meta.Package == "main" {
+	// If the devcard is located in a test file, or in a main package, our
+	// main function must be placed in the same directory.
+	if meta.Test || meta.Package == "main" {
 		return filepath.Dir(meta.Path)
 	}
 
@@ -28,9 +28,10 @@ func DevcardMainDir(meta devcard.DevcardMeta) string {
 		return filepath.Join(parts...)
 	}
 
-	// Otherwise, we may create the directory for our main package anywhere;
-	// we'll use a new directory in the root of the repo.
-	return dir
+	// Otherwise, we may create the directory for our main package anywhere
+	// within the devcard's module; we'll use a new directory in the root of
+	// the module.
+	return filepath.Join(meta.ModuleDir, dir)
 }
 
 func splitPath(path string) []string {
@@ -0,0 +1,233 @@
+// Package ignore implements matching of file paths against the patterns of
+// .gitignore files.
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher decides whether the files of a project must be ignored.
+//
+// It's safe for concurrent use.
+type Matcher struct {
+	root  string
+	rules []rule
+
+	// overrides are the rules of the configured patterns. They're applied
+	// after the rules of .gitignore files.
+	overrides []rule
+}
+
+type rule struct {
+	// base is the dir (relative to the root, in slash-separated form) of the
+	// .gitignore file that contains the rule.
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// New creates a matcher for the files in root. The matcher follows the
+// patterns of .gitignore files in the root and its subdirectories, along with
+// the patterns (in .gitignore format, relative to the root), which take
+// precedence over them. The files matching the include patterns are never
+// ignored, unless they are in an ignored dir.
+//
+// The .git dir is always ignored.
+func New(root string, patterns, include []string) (*Matcher, error) {
+	m := &Matcher{root: root}
+	for _, p := range patterns {
+		if r, ok := parseRule("", p); ok {
+			m.overrides = append(m.overrides, r)
+		}
+	}
+	for _, p := range include {
+		if r, ok := parseRule("", p); ok && !r.negate {
+			r.negate = true
+			m.overrides = append(m.overrides, r)
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case !d.IsDir():
+			return nil
+		case m.Ignored(path, true):
+			return fs.SkipDir
+		}
+
+		patterns, err := readPatterns(filepath.Join(path, ".gitignore"))
+		if err != nil {
+			return err
+		}
+		base, _ := filepath.Rel(root, path)
+		m.addRules(filepath.ToSlash(base), patterns)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading .gitignore files in %s: %w", root, err)
+	}
+	return m, nil
+}
+
+func readPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		patterns = append(patterns, s.Text())
+	}
+	return patterns, s.Err()
+}
+
+func (m *Matcher) addRules(base string, patterns []string) {
+	if base == "." {
+		base = ""
+	}
+	for _, p := range patterns {
+		if r, ok := parseRule(base, p); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+}
+
+func parseRule(base, pattern string) (rule, bool) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return rule{}, false
+	}
+
+	r := rule{base: base}
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return rule{}, false
+	}
+
+	// A pattern with a slash at the beginning or in the middle is relative to
+	// the .gitignore's dir; otherwise, it matches at any level below it.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	expr := globToRegexp(pattern)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+func globToRegexp(glob string) string {
+	s := new(strings.Builder)
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			s.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			s.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			s.WriteString(".*")
+			i++
+		case c == '*':
+			s.WriteString("[^/]*")
+		case c == '?':
+			s.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				s.WriteString(`\[`)
+				break
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			s.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			s.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			s.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return s.String()
+}
+
+// Ignored reports whether the file (or dir, if isDir is true) at path must be
+// ignored. The file is ignored if it matches the patterns, or if any of its
+// parent dirs is ignored.
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		if parts[i] == ".git" {
+			return true
+		}
+		isLast := i == len(parts)-1
+		if m.match(strings.Join(parts[:i+1], "/"), !isLast || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// match applies the rules to the path. As in git, the last matching rule
+// takes precedence.
+func (m *Matcher) match(path string, isDir bool) bool {
+	ignored := applyRules(m.rules, path, isDir, false)
+	return applyRules(m.overrides, path, isDir, ignored)
+}
+
+func applyRules(rules []rule, path string, isDir, ignored bool) bool {
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		p := path
+		if r.base != "" {
+			if !strings.HasPrefix(path, r.base+"/") {
+				continue
+			}
+			p = path[len(r.base)+1:]
+		}
+		if r.re.MatchString(p) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,87 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	root := t.TempDir()
+	gitignore := "*.log\n!keep.log\n/build\ndist/\ndocs/**/*.tmp\nfoo/**\n\\#hash\n"
+	writeFile(t, filepath.Join(root, ".gitignore"), gitignore)
+	writeFile(t, filepath.Join(root, "sub", ".gitignore"), "/local\nnested.txt\n")
+
+	m, err := New(root, []string{"vendor/", "!b.log"}, []string{"*.pb.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.go", false, false},
+		{".git", true, true},
+		{".git/config", false, true},
+
+		// Unanchored patterns match at any level.
+		{"a.log", false, true},
+		{"x/y/a.log", false, true},
+
+		// Negation.
+		{"keep.log", false, false},
+		{"x/keep.log", false, false},
+
+		// Anchored patterns match only relative to the .gitignore's dir.
+		{"build", true, true},
+		{"build/out.bin", false, true},
+		{"x/build", true, false},
+		{"sub/local", false, true},
+		{"local", false, false},
+		{"sub/x/nested.txt", false, true},
+		{"nested.txt", false, false},
+
+		// Directory-only patterns.
+		{"dist", true, true},
+		{"dist", false, false},
+		{"dist/app.js", false, true},
+		{"x/dist/app.js", false, true},
+
+		// Double asterisks.
+		{"docs/a.tmp", false, true},
+		{"docs/x/y/a.tmp", false, true},
+		{"a.tmp", false, false},
+		{"foo/x/y", false, true},
+		{"foo", true, false},
+
+		// Escaped characters.
+		{"#hash", false, true},
+
+		// The configured patterns take precedence over .gitignore.
+		{"vendor", true, true},
+		{"vendor/m/a.go", false, true},
+		{"b.log", false, false},
+
+		// The included files are never ignored, unless their dir is.
+		{"gen/a.pb.go", false, false},
+		{"build/a.pb.go", false, true},
+	}
+	for _, test := range tests {
+		got := m.Ignored(filepath.Join(root, filepath.FromSlash(test.path)), test.isDir)
+		if got != test.ignored {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", test.path, test.isDir, got, test.ignored)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
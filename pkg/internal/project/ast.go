@@ -19,6 +19,9 @@ import (
 )
 
 func (p *Project) updateFile(path string) ([]byte, error) {
+	if path == filepath.Join(p.Dir, "go.work") {
+		return rewriteWork(p.Dir, path)
+	}
 	if filepath.Ext(path) != ".go" {
 		return nil, nil
 	}
@@ -39,19 +42,75 @@ func (p *Project) updateFile(path string) ([]byte, error) {
 	}
 	p.collectDecls(file)
 	p.collectPackage(path, file)
+	p.collectImports(path, file)
 	p.updateDevcardsMeta(path, file)
 	return p.rewriteFile(file)
 }
 
+// collectDecls indexes the top-level declarations of f by their qualified
+// names: "pkg.Func", "pkg.Type", "pkg.Type.Method", "pkg.Const", and "pkg.Var".
+//
+// Grouped type, const, and var declarations are indexed as a whole, so that
+// the source of each name includes the entire group.
 func (p *Project) collectDecls(f *ast.File) {
+	p.declsLock.Lock()
+	defer p.declsLock.Unlock()
 	for _, decl := range f.Decls {
-		if fn, ok := decl.(*ast.FuncDecl); ok {
-			p.decls[f.Name.Name+"."+fn.Name.Name] = &printer.CommentedNode{Node: fn, Comments: f.Comments}
+		node := &printer.CommentedNode{Node: decl, Comments: f.Comments}
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if recv := receiverName(d); recv != "" {
+				name = recv + "." + name
+			}
+			p.decls[f.Name.Name+"."+name] = node
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					p.decls[f.Name.Name+"."+s.Name.Name] = node
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if name.Name != "_" {
+							p.decls[f.Name.Name+"."+name.Name] = node
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// receiverName returns the name of the receiver's type of the method fn, or
+// an empty string if fn is not a method.
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch x := expr.(type) {
+		case *ast.StarExpr:
+			expr = x.X
+		case *ast.ParenExpr:
+			expr = x.X
+		case *ast.IndexExpr:
+			expr = x.X
+		case *ast.IndexListExpr:
+			expr = x.X
+		case *ast.Ident:
+			return x.Name
+		default:
+			return ""
 		}
 	}
 }
 
 func (p *Project) collectPackage(path string, f *ast.File) {
+	if strings.HasSuffix(path, "_test.go") {
+		// Test files might belong to an external test package.
+		return
+	}
 	dir, _ := filepath.Split(path)
 	relDir, err := filepath.Rel(p.Dir, dir)
 	if err != nil {
@@ -60,6 +119,7 @@ func (p *Project) collectPackage(path string, f *ast.File) {
 	p.packages[relDir] = f.Name.Name
 }
 
+// source formats the declaration. The caller must hold p.declsLock.
 func (p *Project) source(decl string) (string, error) {
 	d, ok := p.decls[decl]
 	if !ok {
@@ -74,16 +134,17 @@ func (p *Project) source(decl string) (string, error) {
 	return buf.String(), nil
 }
 
-func importPath(mod, dir, path string) string {
-	relPath, err := filepath.Rel(dir, path)
+// importPath returns the import path of the package in dir, which is relative
+// to the project dir.
+func importPath(m module, dir string) string {
+	rel, err := filepath.Rel(m.Dir, dir)
 	if err != nil {
 		panic(fmt.Errorf("incorrect call to importPath: %w", err))
 	}
-	parent, _ := filepath.Split(relPath)
-	if parent == "" {
-		return mod
+	if rel == "." {
+		return m.Path
 	}
-	return mod + "/" + filepath.Dir(relPath)
+	return m.Path + "/" + filepath.ToSlash(rel)
 }
 
 func (p *Project) updateDevcardsMeta(path string, f *ast.File) {
@@ -98,13 +159,21 @@ func (p *Project) updateDevcardsMeta(path string, f *ast.File) {
 				// We can't reach here, but let's panic just in case.
 				panic(fmt.Errorf("updateDevcardsMeta: %w", err))
 			}
+			m, ok := lookupModule(p.modules, devcardPath)
+			if !ok {
+				// The file isn't a part of any module, so the devcard can't be built.
+				continue
+			}
 			meta := devcard.DevcardMeta{
-				ImportPath: importPath(p.Module, p.Dir, path),
+				ImportPath: importPath(m, filepath.Dir(devcardPath)),
+				ModuleDir:  m.Dir,
 				Package:    f.Name.Name,
 				Path:       devcardPath,
 				Line:       p.fset.Position(fn.Pos()).Line,
 				Name:       fn.Name.Name,
 				Title:      devcardTitle(p.fset, fn),
+				Doc:        strings.TrimSpace(fn.Doc.Text()),
+				Test:       strings.HasSuffix(path, "_test.go"),
 			}
 			p.cardsMeta = append(p.cardsMeta, meta)
 		}
@@ -114,11 +183,14 @@ func (p *Project) updateDevcardsMeta(path string, f *ast.File) {
 }
 
 func (p *Project) rewriteFile(f *ast.File) ([]byte, error) {
+	// The declarations might be being formatted by Source.
+	p.declsLock.Lock()
 	for _, decl := range f.Decls {
 		if f, ok := decl.(*ast.FuncDecl); ok && f.Name.Name == "main" {
 			f.Name.Name = "_main_orig"
 		}
 	}
+	p.declsLock.Unlock()
 
 	buf := new(bytes.Buffer)
 	err := format.Node(buf, p.fset, f)
@@ -0,0 +1,167 @@
+package project
+
+import (
+	"go/ast"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/igorhub/devcard/pkg/internal/file"
+	"github.com/igorhub/devcard/pkg/internal/runner"
+)
+
+// goFile describes a Go file of the project.
+type goFile struct {
+	// Package is the import path of the file's package.
+	Package string
+
+	// Imports are the import paths of the packages imported by the file.
+	Imports []string
+}
+
+// changes tracks the changes in the project since the runners were restarted
+// last time, so that only the affected runners need to be restarted.
+type changes struct {
+	// all is true if the change might affect any devcard.
+	all bool
+
+	// packages are the import paths of the changed packages.
+	packages map[string]bool
+}
+
+func (p *Project) collectImports(path string, f *ast.File) {
+	rel, _ := filepath.Rel(p.Dir, path)
+	m, ok := lookupModule(p.modules, rel)
+	if !ok {
+		delete(p.goFiles, path)
+		return
+	}
+	file := goFile{Package: importPath(m, filepath.Dir(rel))}
+	for _, spec := range f.Imports {
+		if imp, err := strconv.Unquote(spec.Path.Value); err == nil {
+			file.Imports = append(file.Imports, imp)
+		}
+	}
+	p.goFiles[path] = file
+}
+
+// markChanged records the change of the file at path.
+//
+// A non-Go file is considered a part of the innermost package containing it,
+// as it might be embedded into the package, or read by its devcards. The
+// change of a module's file, such as go.sum, might affect any package.
+func (p *Project) markChanged(path string) {
+	switch filepath.Base(path) {
+	case "go.mod", "go.sum", "go.work", "go.work.sum":
+		p.changes.all = true
+		return
+	}
+	rel, _ := filepath.Rel(p.Dir, path)
+	dir := filepath.Dir(rel)
+	if filepath.Ext(path) != ".go" {
+		for _, ok := p.packages[dir]; !ok; _, ok = p.packages[dir] {
+			if dir == "." {
+				p.changes.all = true
+				return
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	m, ok := lookupModule(p.modules, dir)
+	if !ok {
+		p.changes.all = true
+		return
+	}
+	if p.changes.packages == nil {
+		p.changes.packages = make(map[string]bool)
+	}
+	p.changes.packages[importPath(m, dir)] = true
+}
+
+// affected reports whether the package (usually, a devcard's package) is
+// affected by the changes, that is, whether it depends on any of the changed
+// packages, directly or transitively. The graph is built by importGraph.
+func (p *Project) affected(pkg string, graph map[string][]string) bool {
+	if p.changes.all {
+		return true
+	}
+	seen := map[string]bool{}
+	var visit func(pkg string) bool
+	visit = func(pkg string) bool {
+		if seen[pkg] {
+			return false
+		}
+		seen[pkg] = true
+		if p.changes.packages[pkg] {
+			return true
+		}
+		for _, imp := range graph[pkg] {
+			if visit(imp) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(pkg)
+}
+
+// importGraph returns the imports of the project's packages, indexed by the
+// packages' import paths.
+func (p *Project) importGraph() map[string][]string {
+	graph := make(map[string][]string)
+	for _, f := range p.goFiles {
+		graph[f.Package] = append(graph[f.Package], f.Imports...)
+	}
+	return graph
+}
+
+// buildSources returns the dirs of the fork each devcard's main package is
+// built from (see [runner.Sources]).
+func (p *Project) buildSources(graph map[string][]string) runner.Sources {
+	dirs := make(map[string][]string) // by import path
+	sources := runner.Sources{Deps: make(map[string][]string), Packages: make(map[string]bool)}
+	for path, f := range p.goFiles {
+		dir := p.fork.path(filepath.Dir(path))
+		if !sources.Packages[dir] {
+			sources.Packages[dir] = true
+			dirs[f.Package] = append(dirs[f.Package], dir)
+		}
+	}
+
+	for _, meta := range p.cardsMeta {
+		mainDir := filepath.Join(p.fork.dir, file.DevcardMainDir(meta))
+		if _, ok := sources.Deps[mainDir]; ok {
+			continue
+		}
+		deps := []string{mainDir, filepath.Join(p.fork.dir, "go.work"), filepath.Join(p.fork.dir, "go.work.sum")}
+		modules := map[string]bool{}
+		seen := map[string]bool{}
+		var visit func(pkg string)
+		visit = func(pkg string) {
+			if seen[pkg] {
+				return
+			}
+			seen[pkg] = true
+			for _, dir := range dirs[pkg] {
+				if dir != mainDir {
+					deps = append(deps, dir)
+				}
+				rel, _ := filepath.Rel(p.fork.dir, dir)
+				if m, ok := lookupModule(p.modules, rel); ok {
+					modules[m.Dir] = true
+				}
+			}
+			for _, imp := range graph[pkg] {
+				visit(imp)
+			}
+		}
+		visit(meta.ImportPath)
+		for dir := range modules {
+			deps = append(deps, filepath.Join(p.fork.dir, dir, "go.mod"), filepath.Join(p.fork.dir, dir, "go.sum"))
+		}
+		// The order affects the hash of the sources.
+		slices.Sort(deps)
+		sources.Deps[mainDir] = slices.Compact(deps)
+	}
+	return sources
+}
@@ -0,0 +1,115 @@
+package project
+
+import (
+	"go/parser"
+	"go/token"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/internal/file"
+)
+
+// newDepsProject returns a project with the packages a→b→c, a test-only
+// import a→t, and an unrelated package u.
+func newDepsProject(t *testing.T) *Project {
+	t.Helper()
+	p := &Project{
+		packages: map[string]string{},
+		goFiles:  map[string]goFile{},
+		modules:  []module{{Dir: ".", Path: "example.com/m"}},
+	}
+	p.Dir = filepath.FromSlash("/project")
+	p.fork = &fork{p: p, dir: filepath.FromSlash("/fork")}
+
+	sources := map[string]string{
+		"a/a.go":      `package a; import "example.com/m/b"`,
+		"a/a_test.go": `package a; import ("testing"; "example.com/m/t")`,
+		"b/b.go":      `package b; import "example.com/m/c"`,
+		"c/c.go":      `package c; import "fmt"`,
+		"t/t.go":      `package t`,
+		"u/u.go":      `package u`,
+	}
+	fset := token.NewFileSet()
+	for path, src := range sources {
+		path = filepath.Join(p.Dir, filepath.FromSlash(path))
+		f, err := parser.ParseFile(fset, path, src, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.collectPackage(path, f)
+		p.collectImports(path, f)
+	}
+	return p
+}
+
+func TestAffected(t *testing.T) {
+	tests := []struct {
+		changed  string
+		affected []string
+	}{
+		{"c/c.go", []string{"a", "b", "c"}},
+		{"b/b.go", []string{"a", "b"}},
+		{"t/t.go", []string{"a", "t"}},
+		{"u/u.go", []string{"u"}},
+		{"a/testdata/input.json", []string{"a"}},
+		{"README.md", []string{"a", "b", "c", "t", "u"}},
+		{"go.mod", []string{"a", "b", "c", "t", "u"}},
+		{"go.sum", []string{"a", "b", "c", "t", "u"}},
+	}
+	for _, test := range tests {
+		p := newDepsProject(t)
+		p.markChanged(filepath.Join(p.Dir, filepath.FromSlash(test.changed)))
+		graph := p.importGraph()
+		var affected []string
+		for _, pkg := range []string{"a", "b", "c", "t", "u"} {
+			if p.affected("example.com/m/"+pkg, graph) {
+				affected = append(affected, pkg)
+			}
+		}
+		if !slices.Equal(affected, test.affected) {
+			t.Errorf("change of %s: got affected %v, want %v", test.changed, affected, test.affected)
+		}
+	}
+}
+
+func TestBuildSources(t *testing.T) {
+	p := newDepsProject(t)
+	cardA := devcard.DevcardMeta{ImportPath: "example.com/m/a", Package: "a", Path: "a/a.go", Name: "DevcardA"}
+	cardATest := devcard.DevcardMeta{ImportPath: "example.com/m/a", Package: "a", Path: "a/a_test.go", Name: "DevcardATest", Test: true}
+	cardU := devcard.DevcardMeta{ImportPath: "example.com/m/u", Package: "u", Path: "u/u.go", Name: "DevcardU"}
+	p.cardsMeta = DevcardsMetaSlice{cardA, cardATest, cardU}
+
+	sources := p.buildSources(p.importGraph())
+
+	fork := func(paths ...string) []string {
+		var result []string
+		for _, path := range paths {
+			result = append(result, filepath.Join(p.fork.dir, filepath.FromSlash(path)))
+		}
+		slices.Sort(result)
+		return result
+	}
+	moduleFiles := []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+	mainA, mainU := file.DevcardMainDir(cardA), file.DevcardMainDir(cardU)
+	want := map[string][]string{
+		fork(mainA)[0]: fork(append([]string{mainA, "a", "b", "c", "t"}, moduleFiles...)...),
+		fork("a")[0]:   fork(append([]string{"a", "b", "c", "t"}, moduleFiles...)...),
+		fork(mainU)[0]: fork(append([]string{mainU, "u"}, moduleFiles...)...),
+	}
+	if len(sources.Deps) != len(want) {
+		t.Errorf("got deps of %v, want %v", slices.Sorted(maps.Keys(sources.Deps)), slices.Sorted(maps.Keys(want)))
+	}
+	for dir, deps := range want {
+		if !slices.Equal(sources.Deps[dir], deps) {
+			t.Errorf("deps of %s: got %v, want %v", dir, sources.Deps[dir], deps)
+		}
+	}
+
+	packages := slices.Sorted(maps.Keys(sources.Packages))
+	if want := fork("a", "b", "c", "t", "u"); !slices.Equal(packages, want) {
+		t.Errorf("got packages %v, want %v", packages, want)
+	}
+}
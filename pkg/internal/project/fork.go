@@ -3,11 +3,18 @@ package project
 import (
 	"fmt"
 	"os"
+
+	"github.com/igorhub/devcard/pkg/internal/runner"
 )
 
 type fork struct {
-	p   *Project
-	dir string
+	p      *Project
+	dir    string
+	builds *runner.BuildCache
+
+	// testMains are the dirs of the fork where the test mains have been
+	// generated.
+	testMains map[string]bool
 }
 
 func newFork(p *Project) (*fork, error) {
@@ -15,7 +22,12 @@ func newFork(p *Project) (*fork, error) {
 	if err != nil {
 		return nil, fmt.Errorf("new fork: %w", err)
 	}
-	return &fork{p: p, dir: dir}, nil
+	builds, err := runner.NewBuildCache(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("new fork: %w", err)
+	}
+	return &fork{p: p, dir: dir, builds: builds}, nil
 }
 
 func (r *fork) delete() error {
@@ -7,6 +7,7 @@ import (
 	"hash/maphash"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -14,15 +15,21 @@ import (
 	"github.com/igorhub/devcard/pkg/internal/file"
 )
 
-var devcardMainTemplate = makeDevcardMainTemplate()
+var (
+	devcardMainTemplate     = makeTemplate("devcard_main.template", templateText)
+	devcardTestMainTemplate = makeTemplate("devcard_test_main.template", testTemplateText)
+)
 
 //go:embed devcard_main.template
 var templateText string
 
-func makeDevcardMainTemplate() *template.Template {
-	result := template.New("devcard_main.template")
+//go:embed devcard_test_main.template
+var testTemplateText string
+
+func makeTemplate(name, text string) *template.Template {
+	result := template.New(name)
 	result.Funcs(template.FuncMap{"producerName": producerName})
-	result, err := result.Parse(templateText)
+	result, err := result.Parse(text)
 	if err != nil {
 		panic(err)
 	}
@@ -36,14 +43,38 @@ func producerName(devcard string) string {
 var hashSeed = maphash.MakeSeed()
 
 func (f *fork) generateMains() error {
-	for _, cards := range f.p.cardsMeta.GroupByImportPath() {
-		dir := filepath.Join(f.dir, file.DevcardMainDir(cards[0]))
-		os.Mkdir(dir, 0775)
-		err := os.WriteFile(filepath.Join(dir, "gen_devcard_main.go"), devcardMain(cards), 0664)
-		if err != nil {
-			return fmt.Errorf("generate main: %w", err)
+	testMains := map[string]bool{}
+	for _, group := range f.p.cardsMeta.GroupByImportPath() {
+		tests := slices.DeleteFunc(slices.Clone(group), func(meta devcard.DevcardMeta) bool { return !meta.Test })
+		cards := slices.DeleteFunc(group, func(meta devcard.DevcardMeta) bool { return meta.Test })
+
+		if len(cards) > 0 {
+			dir := filepath.Join(f.dir, file.DevcardMainDir(cards[0]))
+			os.Mkdir(dir, 0775)
+			err := os.WriteFile(filepath.Join(dir, "gen_devcard_main.go"), devcardMain(cards), 0664)
+			if err != nil {
+				return fmt.Errorf("generate main: %w", err)
+			}
+		}
+
+		if len(tests) > 0 {
+			dir := filepath.Join(f.dir, file.DevcardMainDir(tests[0]))
+			err := os.WriteFile(filepath.Join(dir, "gen_devcard_main_test.go"), devcardTestMain(tests), 0664)
+			if err != nil {
+				return fmt.Errorf("generate test main: %w", err)
+			}
+			testMains[dir] = true
 		}
 	}
+
+	// The packages which no longer have devcards in their test files must not
+	// keep the test mains referring to the removed devcards.
+	for dir := range f.testMains {
+		if !testMains[dir] {
+			os.Remove(filepath.Join(dir, "gen_devcard_main_test.go"))
+		}
+	}
+	f.testMains = testMains
 	return nil
 }
 
@@ -66,3 +97,35 @@ func devcardMain(cards []devcard.DevcardMeta) []byte {
 	}
 	return b.Bytes()
 }
+
+// devcardTestMain generates a Go source for the test file that runs the
+// devcards located in the test files of a package.
+//
+// If some of the devcards belong to the external test package (the one with
+// the _test suffix), the generated file belongs to it as well, and imports the
+// package under test.
+func devcardTestMain(cards []devcard.DevcardMeta) []byte {
+	data := struct {
+		Cards       DevcardsMetaSlice
+		Package     string
+		MaybeImport string
+	}{Cards: cards, Package: cards[0].Package}
+	for _, meta := range cards {
+		if strings.HasSuffix(meta.Package, "_test") {
+			data.Package = meta.Package
+		}
+	}
+	for _, meta := range cards {
+		if meta.Package != data.Package {
+			data.MaybeImport = fmt.Sprintf("dc \"%s\"", meta.ImportPath)
+			break
+		}
+	}
+
+	var b bytes.Buffer
+	err := devcardTestMainTemplate.Execute(&b, data)
+	if err != nil {
+		panic(fmt.Errorf("devcardTestMainTemplate failed to execute: %w", err))
+	}
+	return b.Bytes()
+}
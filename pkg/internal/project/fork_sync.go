@@ -57,8 +57,10 @@ func (f *fork) syncDir() error {
 		switch {
 		case err != nil:
 			return err
-		case d.Name() == ".git":
+		case d.IsDir() && f.p.ignored.Ignored(path, true):
 			return fs.SkipDir
+		case f.p.ignored.Ignored(path, d.IsDir()):
+			return nil
 		case d.IsDir():
 			_ = os.Mkdir(f.path(path), 0700)
 			return nil
@@ -114,11 +116,17 @@ func isDeletedAlready(path string) bool {
 }
 
 func (f *fork) removeFile(path string) error {
+	n := len(f.p.cardsMeta)
 	f.p.cardsMeta = slices.DeleteFunc(f.p.cardsMeta, func(meta devcard.DevcardMeta) bool {
 		return filepath.Join(f.p.Dir, meta.Path) == path
 	})
+	delete(f.p.goFiles, path)
 
 	_ = os.Remove(f.path(path))
+	if len(f.p.cardsMeta) != n {
+		// The mains must not refer to the removed devcards.
+		return f.generateMains()
+	}
 	return nil
 }
 
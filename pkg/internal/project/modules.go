@@ -0,0 +1,175 @@
+package project
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/igorhub/devcard/pkg/internal/ignore"
+	"golang.org/x/mod/modfile"
+)
+
+// module is a Go module within the project.
+type module struct {
+	// Dir is the relative path (from the project dir) to the module's root.
+	Dir string
+
+	// Path is the module path, as declared in its go.mod.
+	Path string
+}
+
+// discoverModules finds the modules of the project.
+//
+// If the project has go.work in its root, the modules are the ones listed in
+// its "use" directives, except for the ones outside of the project dir (they
+// are built from their original location; see rewriteWork). Otherwise, the
+// modules are defined by go.mod files in the project dir and its
+// subdirectories, except for the ignored ones.
+func discoverModules(projectDir string, ignored *ignore.Matcher) ([]module, error) {
+	var dirs []string
+	workPath := filepath.Join(projectDir, "go.work")
+	data, err := os.ReadFile(workPath)
+	switch {
+	case err == nil:
+		work, err := modfile.ParseWork(workPath, data, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, use := range work.Use {
+			dir := filepath.FromSlash(use.Path)
+			if filepath.IsAbs(dir) {
+				dir, err = filepath.Rel(projectDir, dir)
+			}
+			if err != nil || !filepath.IsLocal(dir) {
+				log.Printf("%s: module %s is outside of the project; its devcards are skipped, and its changes aren't watched", workPath, use.Path)
+				continue
+			}
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		dirs, err = goModDirs(projectDir, ignored)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	var modules []module
+	for _, dir := range dirs {
+		path, err := moduleName(filepath.Join(projectDir, dir))
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module{Dir: dir, Path: path})
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no Go modules found in %s", projectDir)
+	}
+
+	// Longer paths go first, so that lookupModule finds the innermost of the
+	// nested modules.
+	pathLen := func(m module) int {
+		if m.Dir == "." {
+			return 0
+		}
+		return len(m.Dir)
+	}
+	slices.SortFunc(modules, func(a, b module) int {
+		return cmp.Compare(pathLen(b), pathLen(a))
+	})
+	return modules, nil
+}
+
+// rewriteWork returns the content of the project's go.work for the fork. As
+// the fork lives in another dir, the relative paths of the modules outside of
+// the project dir are replaced with absolute ones.
+func rewriteWork(projectDir, workPath string) ([]byte, error) {
+	data, err := os.ReadFile(workPath)
+	if err != nil {
+		return nil, err
+	}
+	work, err := modfile.ParseWork(workPath, data, nil)
+	if err != nil {
+		// Leave it to the go command to report the error.
+		return data, nil
+	}
+	rewritten := false
+	for _, use := range slices.Clone(work.Use) {
+		dir := filepath.FromSlash(use.Path)
+		if filepath.IsAbs(dir) || filepath.IsLocal(dir) {
+			continue
+		}
+		err = errors.Join(
+			work.DropUse(use.Path),
+			work.AddUse(filepath.ToSlash(filepath.Join(projectDir, dir)), use.ModulePath))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", workPath, err)
+		}
+		rewritten = true
+	}
+	if !rewritten {
+		return data, nil
+	}
+	work.Cleanup()
+	return modfile.Format(work.Syntax), nil
+}
+
+// goModDirs returns the relative paths of the dirs containing go.mod.
+func goModDirs(projectDir string, ignored *ignore.Matcher) ([]string, error) {
+	var result []string
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir() && path != projectDir && isIgnoredByGo(d.Name()):
+			return fs.SkipDir
+		case d.IsDir() && ignored.Ignored(path, true):
+			return fs.SkipDir
+		case d.IsDir() || d.Name() != "go.mod":
+			return nil
+		}
+		dir, err := filepath.Rel(projectDir, filepath.Dir(path))
+		result = append(result, dir)
+		return err
+	})
+	return result, err
+}
+
+// isIgnoredByGo reports whether the go command ignores the directory.
+func isIgnoredByGo(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// lookupModule returns the module containing the file at path (relative to
+// the project dir).
+func lookupModule(modules []module, path string) (module, bool) {
+	for _, m := range modules {
+		if m.Dir == "." {
+			return m, true
+		}
+		if rel, err := filepath.Rel(m.Dir, path); err == nil && filepath.IsLocal(rel) {
+			return m, true
+		}
+	}
+	return module{}, false
+}
+
+func moduleName(moduleDir string) (string, error) {
+	path := filepath.Join(moduleDir, "go.mod")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	mod, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return "", err
+	}
+	return mod.Module.Syntax.Token[1], nil
+}
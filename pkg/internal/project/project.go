@@ -3,8 +3,8 @@ package project
 import (
 	"go/printer"
 	"go/token"
-	"os"
-	"path/filepath"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -12,13 +12,12 @@ import (
 	"github.com/igorhub/devcard/pkg/internal/bundle"
 	"github.com/igorhub/devcard/pkg/internal/codegenerator"
 	"github.com/igorhub/devcard/pkg/internal/config"
+	"github.com/igorhub/devcard/pkg/internal/ignore"
 	"github.com/igorhub/devcard/pkg/internal/runner"
-	"golang.org/x/mod/modfile"
 )
 
 type Project struct {
 	config.ProjectConfig
-	Module string
 
 	cfg        *config.Config
 	cardsMeta  DevcardsMetaSlice
@@ -26,12 +25,26 @@ type Project struct {
 	events     chan projectEvent
 	restarts   chan projectEvent
 	fatalError error
+	closed     bool
+	modules    []module
+	ignored    *ignore.Matcher
+	goFiles    map[string]goFile // by absolute path
+	changes    changes
 
-	fork      *fork
-	watcher   *fsnotify.Watcher
+	// runnersError is the error the runners were restarted with last time.
+	runnersError error
+
+	fork    *fork
+	watcher *fsnotify.Watcher
+
+	// declsLock guards fset and decls, as they're read by the runners (see
+	// Source) besides the event loop.
+	declsLock sync.Mutex
 	fset      *token.FileSet
 	decls     map[string]*printer.CommentedNode
+
 	runners   map[*runner.Runner]struct{}
+	history   *runner.History
 	generator *codegenerator.Generator
 }
 
@@ -42,6 +55,7 @@ func NewProject(cfg *config.Config, projectConfig config.ProjectConfig) *Project
 		packages:      make(map[string]string),
 		events:        make(chan projectEvent, 256),
 		runners:       make(map[*runner.Runner]struct{}),
+		history:       runner.NewHistory(projectConfig.History),
 		generator:     codegenerator.New(projectConfig),
 	}
 
@@ -49,7 +63,10 @@ func NewProject(cfg *config.Config, projectConfig config.ProjectConfig) *Project
 	bundle.Bundle(p.events, p.restarts, 30*time.Millisecond)
 
 	go p.runEventLoop()
-	p.Module, p.fatalError = moduleName(p.Dir)
+	p.ignored, p.fatalError = ignore.New(p.Dir, p.Ignore, p.NeverIgnore)
+	if p.fatalError == nil {
+		p.modules, p.fatalError = discoverModules(p.Dir, p.ignored)
+	}
 	if p.fatalError == nil {
 		p.fork, p.fatalError = newFork(p)
 	}
@@ -72,18 +89,33 @@ func (p *Project) Restart(cfg *config.Config) {
 	p.events <- evRestart{}
 }
 
+// Sync waits until the project processes all pending events, and returns the
+// error that prevents the project's devcards from running, if any.
+func (p *Project) Sync() error {
+	ch := make(chan error)
+	p.events <- evSync{err: ch}
+	return <-ch
+}
+
 func (p *Project) GetDevcards() DevcardsMetaSlice {
 	ch := make(chan []devcard.DevcardMeta)
 	p.events <- evGetDevcards{result: ch}
 	return <-ch
 }
 
-func (p *Project) StartRunner(devcardName string) string {
+// StartRunner starts a runner for the devcard with the given parameters (see
+// [devcard.Devcard.Param]), and returns the runner's id.
+func (p *Project) StartRunner(devcardName string, params url.Values) string {
 	id := make(chan string)
-	p.events <- evStartRunner{devcardName, id}
+	p.events <- evStartRunner{devcardName, params, id}
 	return <-id
 }
 
+// History returns the latest runs of the devcard, the latest run first.
+func (p *Project) History(devcardName string) []runner.Run {
+	return p.history.Runs(devcardName)
+}
+
 func (p *Project) GetRunner(id string) chan any {
 	updates := make(chan chan any)
 	p.events <- evGetRunner{id, updates}
@@ -94,15 +126,47 @@ func (p *Project) StopRunner(runnerId string) {
 	p.events <- evStopRunner{runnerId}
 }
 
-func moduleName(projectDir string) (string, error) {
-	path := filepath.Join(projectDir, "go.mod")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-	mod, err := modfile.Parse(path, data, nil)
-	if err != nil {
-		return "", err
-	}
-	return mod.Module.Syntax.Token[1], nil
+// SetRunnerParam sets the value of the devcard's input in the runner and
+// re-runs the devcard.
+func (p *Project) SetRunnerParam(runnerId, name, value string) {
+	p.events <- evWithRunner{runnerId, func(r *runner.Runner) { r.SetParam(name, value) }}
+}
+
+// InterruptRunner stops the devcard running in the runner.
+func (p *Project) InterruptRunner(runnerId string) {
+	p.events <- evWithRunner{runnerId, (*runner.Runner).Interrupt}
+}
+
+// RerunRunner runs the runner's devcard again.
+func (p *Project) RerunRunner(runnerId string) {
+	p.events <- evWithRunner{runnerId, (*runner.Runner).Rerun}
+}
+
+// RunnerFile returns the path to the file produced by the devcard in the
+// runner identified by token (see [runner.Runner.File]).
+func (p *Project) RunnerFile(token, name string) (string, bool) {
+	result := make(chan string, 1)
+	p.events <- evGetRunnerFile{token, name, result}
+	path, ok := <-result
+	return path, ok
+}
+
+// RunnerSubtree returns the rendered children of the node of a value's tree
+// in the runner identified by token (see [runner.Runner.Subtree]).
+func (p *Project) RunnerSubtree(token, id string) (string, bool) {
+	result := make(chan string, 1)
+	p.events <- evGetRunnerSubtree{token, id, result}
+	html, ok := <-result
+	return html, ok
+}
+
+// Source returns the formatted source of the declaration decl, such as
+// "pkg.Func" or "pkg.Type.Method", along with its doc comment.
+//
+// Unlike the other methods, it doesn't go through the event loop: it's
+// called by the runners, which the event loop might be waiting for.
+func (p *Project) Source(decl string) (string, error) {
+	p.declsLock.Lock()
+	defer p.declsLock.Unlock()
+	return p.source(decl)
 }
@@ -6,9 +6,12 @@ import (
 	"go/printer"
 	"go/token"
 	"log"
+	"net/url"
+	"path/filepath"
 	"time"
 
 	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/internal/ignore"
 	"github.com/igorhub/devcard/pkg/internal/runner"
 )
 
@@ -38,22 +41,53 @@ type evUpdateFile struct {
 }
 
 func (e evUpdateFile) act(p *Project) error {
+	if p.closed {
+		return nil
+	}
+	if changesLayout(e.path) {
+		p.events <- evRestart{}
+		return nil
+	}
 	err := p.fork.syncFile(e.path, false)
+	// The files written by the code generators (which run on
+	// evRestartRunners) arrive here as the watcher's events as well, so the
+	// runners depending on them are restarted by the next evRestartRunners.
+	p.markChanged(e.path)
 	p.generator.AddFile(e.path)
 	p.restarts <- evRestartRunners{}
 	return err
 }
 
+// changesLayout reports whether changing the file might change the set of the
+// project's modules or ignored files.
+func changesLayout(path string) bool {
+	switch filepath.Base(path) {
+	case "go.mod", "go.work", ".gitignore":
+		return true
+	}
+	return false
+}
+
 type evRemoveFile struct {
 	path string
 }
 
 func (e evRemoveFile) act(p *Project) error {
+	if p.closed {
+		return nil
+	}
+	if changesLayout(e.path) {
+		p.events <- evRestart{}
+		return nil
+	}
+	p.markChanged(e.path)
 	err := p.fork.removeFile(e.path)
 	p.restarts <- evRestartRunners{}
 	return err
 }
 
+// evRestartRunners restarts the runners affected by the changes since the
+// previous evRestartRunners.
 type evRestartRunners struct{}
 
 // MUST return nil
@@ -62,12 +96,27 @@ func (e evRestartRunners) act(p *Project) error {
 	if err == nil {
 		err = p.generator.Run()
 	}
+	if err != nil || p.runnersError != nil {
+		// The runners must either show the error, or recover from it.
+		p.changes.all = true
+	}
+	graph := p.importGraph()
+	if p.fork != nil {
+		p.fork.builds.SetSources(p.buildSources(graph))
+	}
 	for r := range p.runners {
-		if _, err2 := p.findDevcardMeta(r.DevcardName); err2 != nil {
-			err = err2
+		meta, err2 := p.findDevcardMeta(r.DevcardName)
+		switch {
+		case err != nil:
+			r.Restart(p.cfg, err)
+		case err2 != nil:
+			r.Restart(p.cfg, err2)
+		case p.affected(meta.ImportPath, graph):
+			r.Restart(p.cfg, nil)
 		}
-		r.Restart(p.cfg, err)
 	}
+	p.runnersError = err
+	p.changes = changes{}
 	return nil
 }
 
@@ -81,18 +130,6 @@ func (e evGetDevcards) act(p *Project) error {
 	return nil
 }
 
-type evGetSource struct {
-	decl   string
-	source string
-	err    error
-	result chan<- evGetSource
-}
-
-func (e evGetSource) act(p *Project) error {
-	// TODO: implement
-	return nil
-}
-
 type retryError struct {
 	retryN int
 	err    error
@@ -124,6 +161,9 @@ type evRestart struct {
 }
 
 func (e evRestart) act(p *Project) error {
+	if p.closed {
+		return nil
+	}
 	if p.watcher != nil {
 		if err := p.watcher.Close(); err != nil {
 			return err
@@ -138,17 +178,37 @@ func (e evRestart) act(p *Project) error {
 		p.fork = fork
 	}
 
+	ignored, err := ignore.New(p.Dir, p.Ignore, p.NeverIgnore)
+	if err != nil {
+		p.fatalError = err
+		return newRetryError(e.lastError, err)
+	}
+	p.ignored = ignored
+
+	modules, err := discoverModules(p.Dir, p.ignored)
+	if err != nil {
+		p.fatalError = err
+		return newRetryError(e.lastError, err)
+	}
+	p.modules = modules
+
 	p.cardsMeta = nil
 	p.packages = map[string]string{}
+	p.goFiles = map[string]goFile{}
+	p.changes.all = true
+	p.declsLock.Lock()
 	p.fset = token.NewFileSet()
 	p.decls = make(map[string]*printer.CommentedNode)
-	err := p.fork.syncAll()
+	p.declsLock.Unlock()
+	err = p.fork.syncAll()
 	if err != nil {
+		p.fatalError = err
 		return newRetryError(e.lastError, err)
 	}
 
-	p.watcher, err = startWatcher(p.Dir, p.events)
+	p.watcher, err = startWatcher(p.Dir, p.ignored, p.events)
 	if err != nil {
+		p.fatalError = err
 		return newRetryError(e.lastError, err)
 	}
 
@@ -157,8 +217,19 @@ func (e evRestart) act(p *Project) error {
 	return nil
 }
 
+type evSync struct {
+	err chan<- error
+}
+
+func (e evSync) act(p *Project) error {
+	e.err <- p.fatalError
+	close(e.err)
+	return nil
+}
+
 type evStartRunner struct {
 	devcardName string
+	params      url.Values
 	id          chan<- string
 }
 
@@ -171,7 +242,15 @@ func (e evStartRunner) act(p *Project) error {
 	if err != nil {
 		r = runner.StartFakeRunner(p.cfg, err)
 	} else {
-		r = runner.Start(p.cfg, p.fork.dir, meta)
+		p.fork.builds.SetSources(p.buildSources(p.importGraph()))
+		r = runner.Start(p.cfg, runner.Env{
+			Dir:      p.fork.dir,
+			Source:   p.Source,
+			Builds:   p.fork.builds,
+			Timeout:  p.Timeout,
+			History:  p.history,
+			RunTests: p.RunTests,
+		}, meta, e.params)
 	}
 	p.runners[r] = struct{}{}
 	e.id <- r.Id
@@ -209,6 +288,58 @@ func (e evStopRunner) act(p *Project) error {
 	return nil
 }
 
+type evGetRunnerFile struct {
+	token, name string
+	result      chan<- string
+}
+
+func (e evGetRunnerFile) act(p *Project) error {
+	for r := range p.runners {
+		if r.Token == e.token {
+			if path, ok := r.File(e.name); ok {
+				e.result <- path
+			}
+			break
+		}
+	}
+	close(e.result)
+	return nil
+}
+
+type evGetRunnerSubtree struct {
+	token, id string
+	result    chan<- string
+}
+
+func (e evGetRunnerSubtree) act(p *Project) error {
+	for r := range p.runners {
+		if r.Token == e.token {
+			if html, ok := r.Subtree(e.id); ok {
+				e.result <- html
+			}
+			break
+		}
+	}
+	close(e.result)
+	return nil
+}
+
+// evWithRunner calls f with the runner.
+type evWithRunner struct {
+	runnerId string
+	f        func(r *runner.Runner)
+}
+
+func (e evWithRunner) act(p *Project) error {
+	for r := range p.runners {
+		if r.Id == e.runnerId {
+			e.f(r)
+			break
+		}
+	}
+	return nil
+}
+
 func (p *Project) findDevcardMeta(devcardName string) (devcard.DevcardMeta, error) {
 	for _, meta := range p.cardsMeta {
 		if meta.Name == devcardName {
@@ -234,6 +365,7 @@ type evShutdown struct {
 }
 
 func (e evShutdown) act(p *Project) error {
+	p.closed = true
 	for r := range p.runners {
 		r.Shutdown()
 	}
@@ -244,7 +376,7 @@ func (e evShutdown) act(p *Project) error {
 		p.watcher = nil
 	}
 	if p.fork != nil {
-		errF = p.fork.delete()
+		errF = errors.Join(p.fork.delete(), p.fork.builds.Close())
 		p.fork = nil
 	}
 	err := errors.Join(errW, errF)
@@ -8,15 +8,16 @@ import (
 	"slices"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/igorhub/devcard/pkg/internal/ignore"
 )
 
-func startWatcher(dir string, events chan projectEvent) (*fsnotify.Watcher, error) {
+func startWatcher(dir string, ignored *ignore.Matcher, events chan projectEvent) (*fsnotify.Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("start watcher in %q: %w", dir, err)
 	}
 
-	watchDirs, err := subdirs(dir)
+	watchDirs, err := subdirs(dir, ignored)
 	if err != nil {
 		return nil, fmt.Errorf("start watcher: %w", err)
 	}
@@ -47,6 +48,9 @@ func startWatcher(dir string, events chan projectEvent) (*fsnotify.Watcher, erro
 				if !ok {
 					return
 				}
+				if ignored.Ignored(e.Name, isDir(e.Name)) {
+					continue
+				}
 
 				switch e.Op {
 				case fsnotify.Create, fsnotify.Write:
@@ -78,7 +82,7 @@ func startWatcher(dir string, events chan projectEvent) (*fsnotify.Watcher, erro
 	return watcher, nil
 }
 
-func subdirs(projectDir string) ([]string, error) {
+func subdirs(projectDir string, ignored *ignore.Matcher) ([]string, error) {
 	var result []string
 	if !filepath.IsAbs(projectDir) {
 		panic(fmt.Errorf("projectDir %q must be an absolute path", projectDir))
@@ -89,7 +93,7 @@ func subdirs(projectDir string) ([]string, error) {
 			return err
 		case !d.IsDir():
 			return nil
-		case d.Name() == ".git":
+		case ignored.Ignored(path, true):
 			return filepath.SkipDir
 		}
 		result = append(result, path)
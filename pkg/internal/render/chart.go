@@ -0,0 +1,410 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/igorhub/devcard"
+)
+
+const (
+	chartWidth   = 640
+	chartHeight  = 360
+	marginLeft   = 64
+	marginRight  = 24
+	marginTop    = 36
+	marginBottom = 56
+	legendHeight = 24
+)
+
+var chartPalette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+func chartColor(i int) string {
+	return chartPalette[i%len(chartPalette)]
+}
+
+// chartFrame maps data coordinates into the plotting area of the chart.
+type chartFrame struct {
+	left, top, width, height float64
+	xmin, xmax, ymin, ymax   float64
+}
+
+func (f chartFrame) x(v float64) float64 {
+	return f.left + fraction(v, f.xmin, f.xmax)*f.width
+}
+
+func (f chartFrame) y(v float64) float64 {
+	return f.top + f.height - fraction(v, f.ymin, f.ymax)*f.height
+}
+
+// fraction returns the position of v within [lo, hi] as a fraction of the
+// range. A degenerate range puts every value in its middle.
+func fraction(v, lo, hi float64) float64 {
+	// Halve the operands, so that the differences don't overflow.
+	r := (v/2 - lo/2) / (hi/2 - lo/2)
+	if !isFinite(r) {
+		return 0.5
+	}
+	return r
+}
+
+type chart struct {
+	cell   *devcard.ChartCell
+	frame  chartFrame
+	height float64
+	s      *strings.Builder
+}
+
+func renderChart(b *devcard.ChartCell) string {
+	if len(b.Series) == 0 {
+		return ""
+	}
+	// The cell might be built by hand, bypassing the checks of devcard's
+	// constructors.
+	for i, s := range b.Series {
+		if len(s.X) > 0 && len(s.X) != len(s.Y) {
+			return renderError("ChartCell error", fmt.Sprintf("series %d has %d X and %d Y coordinates", i+1, len(s.X), len(s.Y)))
+		}
+	}
+
+	c := &chart{cell: b, height: chartHeight, s: new(strings.Builder)}
+	if c.hasLegend() {
+		c.height += legendHeight
+	}
+	c.frame = chartFrame{
+		left:   marginLeft,
+		top:    marginTop,
+		width:  chartWidth - marginLeft - marginRight,
+		height: chartHeight - marginTop - marginBottom,
+	}
+
+	fmt.Fprintf(c.s, `<svg class="-dc-chart" xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %v" width="%d" `+
+		`style="max-width: 100%%; height: auto" font-family="sans-serif" font-size="12" fill="currentColor">`,
+		chartWidth, c.height, chartWidth)
+	if b.Title != "" {
+		fmt.Fprintf(c.s, `<text x="%d" y="20" text-anchor="middle" font-size="14" font-weight="bold">%s</text>`,
+			chartWidth/2, html.EscapeString(b.Title))
+	}
+
+	switch b.Kind {
+	case devcard.ChartLine, devcard.ChartScatter:
+		c.renderXY()
+	case devcard.ChartBar:
+		c.renderBars()
+	case devcard.ChartHistogram:
+		c.renderHistogram()
+	default:
+		return renderError("ChartCell error", fmt.Sprintf("unknown kind of chart: %q", b.Kind))
+	}
+
+	c.renderAxisLabels()
+	c.renderLegend()
+	c.s.WriteString("</svg>")
+	return c.s.String()
+}
+
+func (c *chart) hasLegend() bool {
+	return len(c.cell.Series) > 1 || c.cell.Series[0].Name != ""
+}
+
+func seriesX(s devcard.ChartSeries, i int) float64 {
+	if len(s.X) > 0 {
+		return s.X[i]
+	}
+	return float64(i)
+}
+
+func (c *chart) renderXY() {
+	xmin, xmax := math.Inf(1), math.Inf(-1)
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, s := range c.cell.Series {
+		for i, y := range s.Y {
+			x := seriesX(s, i)
+			if !isFinite(x) || !isFinite(y) {
+				continue
+			}
+			xmin, xmax = min(xmin, x), max(xmax, x)
+			ymin, ymax = min(ymin, y), max(ymax, y)
+		}
+	}
+	xticks := c.setXDomain(xmin, xmax)
+	yticks := c.setYDomain(ymin, ymax)
+	c.renderGrid(yticks)
+	c.renderXTicks(xticks)
+
+	f := c.frame
+	for i, s := range c.cell.Series {
+		color := chartColor(i)
+		if c.cell.Kind == devcard.ChartScatter {
+			for j, y := range s.Y {
+				x := seriesX(s, j)
+				if !isFinite(x) || !isFinite(y) {
+					continue
+				}
+				fmt.Fprintf(c.s, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s" fill-opacity="0.8"/>`, f.x(x), f.y(y), color)
+			}
+			continue
+		}
+
+		path := new(strings.Builder)
+		cmd := "M"
+		for j, y := range s.Y {
+			x := seriesX(s, j)
+			if !isFinite(x) || !isFinite(y) {
+				// Break the line at missing points.
+				cmd = "M"
+				continue
+			}
+			fmt.Fprintf(path, "%s%.1f %.1f ", cmd, f.x(x), f.y(y))
+			cmd = "L"
+		}
+		fmt.Fprintf(c.s, `<path d="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round"/>`,
+			strings.TrimSpace(path.String()), color)
+	}
+}
+
+func (c *chart) renderBars() {
+	n := 0
+	ymin, ymax := 0.0, 0.0
+	for _, s := range c.cell.Series {
+		n = max(n, len(s.Y))
+		for _, y := range s.Y {
+			if isFinite(y) {
+				ymin, ymax = min(ymin, y), max(ymax, y)
+			}
+		}
+	}
+	n = max(n, len(c.cell.Labels))
+	if n == 0 {
+		return
+	}
+
+	c.frame.xmin, c.frame.xmax = 0, float64(n)
+	yticks := c.setYDomain(ymin, ymax)
+	c.renderGrid(yticks)
+
+	f := c.frame
+	band := f.width / float64(n)
+	group := band * 0.8
+	barWidth := group / float64(len(c.cell.Series))
+	for i := 0; i < n; i++ {
+		label := strconv.Itoa(i + 1)
+		if i < len(c.cell.Labels) {
+			label = c.cell.Labels[i]
+		}
+		c.renderXTick(f.left+band*(float64(i)+0.5), label)
+	}
+	for j, s := range c.cell.Series {
+		for i, y := range s.Y {
+			if !isFinite(y) {
+				continue
+			}
+			x := f.left + band*float64(i) + (band-group)/2 + barWidth*float64(j)
+			top, bottom := f.y(max(y, 0)), f.y(min(y, 0))
+			fmt.Fprintf(c.s, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`,
+				x, top, barWidth, bottom-top, chartColor(j), formatTick(y))
+		}
+	}
+}
+
+func (c *chart) renderHistogram() {
+	var samples []float64
+	for _, s := range c.cell.Series {
+		for _, y := range s.Y {
+			if isFinite(y) {
+				samples = append(samples, y)
+			}
+		}
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	lo, hi := widen(slices.Min(samples), slices.Max(samples))
+	bins := c.cell.Bins
+	if bins <= 0 {
+		// Sturges' rule.
+		bins = int(math.Ceil(math.Log2(float64(len(samples))))) + 1
+	}
+	bins = min(bins, 1000)
+	binWidth := hi/float64(bins) - lo/float64(bins)
+
+	counts := make([][]int, len(c.cell.Series))
+	maxCount := 0
+	for j, s := range c.cell.Series {
+		counts[j] = make([]int, bins)
+		for _, y := range s.Y {
+			if !isFinite(y) {
+				continue
+			}
+			i := min(int(fraction(y, lo, hi)*float64(bins)), bins-1)
+			counts[j][i]++
+			maxCount = max(maxCount, counts[j][i])
+		}
+	}
+
+	c.frame.xmin, c.frame.xmax = lo, hi
+	yticks := c.setYDomain(0, float64(maxCount))
+	c.renderGrid(yticks)
+	for _, tick := range niceTicks(lo, hi) {
+		if tick >= lo && tick <= hi {
+			c.renderXTick(c.frame.x(tick), formatTick(tick))
+		}
+	}
+
+	f := c.frame
+	opacity := 1.0
+	if len(c.cell.Series) > 1 {
+		opacity = 0.6
+	}
+	for j := range c.cell.Series {
+		for i, count := range counts[j] {
+			if count == 0 {
+				continue
+			}
+			x0, x1 := f.x(lo+binWidth*float64(i)), f.x(lo+binWidth*float64(i+1))
+			fmt.Fprintf(c.s, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="%.1f" stroke="currentColor" stroke-opacity="0.3">`+
+				`<title>[%s, %s): %d</title></rect>`,
+				x0, f.y(float64(count)), x1-x0, f.y(0)-f.y(float64(count)), chartColor(j), opacity,
+				formatTick(lo+binWidth*float64(i)), formatTick(lo+binWidth*float64(i+1)), count)
+		}
+	}
+}
+
+// setXDomain sets the X range of the chart and returns its ticks.
+func (c *chart) setXDomain(lo, hi float64) []float64 {
+	ticks := niceTicks(lo, hi)
+	c.frame.xmin, c.frame.xmax = ticks[0], ticks[len(ticks)-1]
+	return ticks
+}
+
+// setYDomain sets the Y range of the chart and returns its ticks.
+func (c *chart) setYDomain(lo, hi float64) []float64 {
+	ticks := niceTicks(lo, hi)
+	c.frame.ymin, c.frame.ymax = ticks[0], ticks[len(ticks)-1]
+	return ticks
+}
+
+func (c *chart) renderGrid(yticks []float64) {
+	f := c.frame
+	for _, tick := range yticks {
+		y := f.y(tick)
+		fmt.Fprintf(c.s, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="currentColor" stroke-opacity="0.15"/>`,
+			f.left, y, f.left+f.width, y)
+		fmt.Fprintf(c.s, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`,
+			f.left-6, y, formatTick(tick))
+	}
+	fmt.Fprintf(c.s, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="currentColor" stroke-opacity="0.6"/>`,
+		f.left, f.top, f.left, f.top+f.height)
+	fmt.Fprintf(c.s, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="currentColor" stroke-opacity="0.6"/>`,
+		f.left, f.top+f.height, f.left+f.width, f.top+f.height)
+}
+
+func (c *chart) renderXTicks(ticks []float64) {
+	for _, tick := range ticks {
+		c.renderXTick(c.frame.x(tick), formatTick(tick))
+	}
+}
+
+func (c *chart) renderXTick(x float64, label string) {
+	bottom := c.frame.top + c.frame.height
+	fmt.Fprintf(c.s, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="currentColor" stroke-opacity="0.6"/>`,
+		x, bottom, x, bottom+4)
+	fmt.Fprintf(c.s, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`,
+		x, bottom+18, html.EscapeString(label))
+}
+
+func (c *chart) renderAxisLabels() {
+	f := c.frame
+	if c.cell.XLabel != "" {
+		fmt.Fprintf(c.s, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`,
+			f.left+f.width/2, f.top+f.height+40, html.EscapeString(c.cell.XLabel))
+	}
+	if c.cell.YLabel != "" {
+		fmt.Fprintf(c.s, `<text transform="translate(16 %.1f) rotate(-90)" text-anchor="middle">%s</text>`,
+			f.top+f.height/2, html.EscapeString(c.cell.YLabel))
+	}
+}
+
+func (c *chart) renderLegend() {
+	if !c.hasLegend() {
+		return
+	}
+	x, y := c.frame.left, float64(chartHeight)
+	for i, s := range c.cell.Series {
+		name := s.Name
+		if name == "" {
+			name = "series " + strconv.Itoa(i+1)
+		}
+		fmt.Fprintf(c.s, `<rect x="%.1f" y="%.1f" width="12" height="12" fill="%s"/>`, x, y-10, chartColor(i))
+		fmt.Fprintf(c.s, `<text x="%.1f" y="%.1f">%s</text>`, x+16, y, html.EscapeString(name))
+		x += 16 + 7*float64(len(name)) + 16
+	}
+}
+
+// niceTicks returns evenly spaced round numbers that cover the range [lo, hi].
+func niceTicks(lo, hi float64) []float64 {
+	if !isFinite(lo) || !isFinite(hi) {
+		lo, hi = 0, 1
+	}
+	lo, hi = widen(lo, hi)
+
+	const targetTicks = 5
+	// Divide before subtracting, so that a range wider than MaxFloat64
+	// doesn't overflow.
+	rawStep := hi/targetTicks - lo/targetTicks
+	magnitude := math.Pow(10, math.Floor(math.Log10(rawStep)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		step = m * magnitude
+		if step >= rawStep {
+			break
+		}
+	}
+	if step == 0 || !isFinite(step) {
+		return []float64{lo}
+	}
+
+	start, end := math.Floor(lo/step), math.Ceil(hi/step)
+	if !isFinite(start) || !isFinite(end) {
+		return []float64{lo}
+	}
+	// The count is bounded by the choice of step, but rounding errors of huge
+	// values must not turn it into an endless loop.
+	const maxTicks = 50
+	n := min(int(end-start), maxTicks)
+	ticks := make([]float64, 0, n+1)
+	for i := 0; i <= n; i++ {
+		tick := (start + float64(i)) * step
+		ticks = append(ticks, max(min(tick, math.MaxFloat64), -math.MaxFloat64))
+	}
+	return ticks
+}
+
+// widen widens a degenerate range [v, v] by an amount relative to v, so that
+// the range stays non-empty for huge values.
+func widen(lo, hi float64) (float64, float64) {
+	if lo != hi {
+		return lo, hi
+	}
+	d := max(math.Abs(lo)*1e-9, 1)
+	return max(lo-d, -math.MaxFloat64), min(hi+d, math.MaxFloat64)
+}
+
+func formatTick(v float64) string {
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/igorhub/devcard"
+)
+
+func TestNiceTicks(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi float64
+	}{
+		{"regular", 0, 10},
+		{"negative", -3.5, 7.2},
+		{"constant", 5, 5},
+		{"constant zero", 0, 0},
+		{"constant huge", 1e17, 1e17},
+		{"constant max", math.MaxFloat64, math.MaxFloat64},
+		{"huge", 1e20, 1e20 + 1e6},
+		{"overflowing", -math.MaxFloat64, math.MaxFloat64},
+		{"infinite", math.Inf(-1), math.Inf(1)},
+		{"nan", math.NaN(), 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ticks := niceTicks(test.lo, test.hi)
+			if len(ticks) == 0 || len(ticks) > 51 {
+				t.Fatalf("got %d ticks: %v", len(ticks), ticks)
+			}
+			for _, tick := range ticks {
+				if !isFinite(tick) {
+					t.Fatalf("got a non-finite tick: %v", ticks)
+				}
+			}
+			if isFinite(test.lo) && isFinite(test.hi) && (ticks[0] > test.lo || ticks[len(ticks)-1] < test.hi) {
+				t.Errorf("ticks %v don't cover [%v, %v]", ticks, test.lo, test.hi)
+			}
+			f := chartFrame{width: 100, height: 100, xmin: ticks[0], xmax: ticks[len(ticks)-1]}
+			for _, tick := range ticks {
+				if x := f.x(tick); !isFinite(x) {
+					t.Errorf("tick %v has a non-finite coordinate", tick)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderChartMismatchedSeries(t *testing.T) {
+	cell := &devcard.ChartCell{
+		Kind:   devcard.ChartLine,
+		Series: []devcard.ChartSeries{{X: []float64{1}, Y: []float64{1, 2, 3}}},
+	}
+	if s := renderChart(cell); !strings.Contains(s, "1 X and 3 Y coordinates") {
+		t.Errorf("expected an error, got:\n%s", s)
+	}
+}
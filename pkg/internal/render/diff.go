@@ -0,0 +1,137 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/runtime"
+)
+
+// diffContext is the number of unchanged lines shown around the changes.
+const diffContext = 3
+
+func renderDiff(b *devcard.DiffCell) string {
+	if len(b.Lines) == 0 {
+		return ""
+	}
+
+	s := new(strings.Builder)
+	s.WriteString(`<table class="-dc-diff">`)
+	if b.LabelA != "" || b.LabelB != "" {
+		if b.SideBySide {
+			fmt.Fprintf(s, `<thead><tr><th>%s</th><th>%s</th></tr></thead>`,
+				html.EscapeString(b.LabelA), html.EscapeString(b.LabelB))
+		} else {
+			fmt.Fprintf(s, `<thead><tr><th>-&nbsp;%s<br>+&nbsp;%s</th></tr></thead>`,
+				html.EscapeString(b.LabelA), html.EscapeString(b.LabelB))
+		}
+	}
+	s.WriteString(`<tbody>`)
+	if b.SideBySide {
+		renderSideBySideDiff(s, b.Lines)
+	} else {
+		renderUnifiedDiff(s, b.Lines)
+	}
+	s.WriteString(`</tbody></table>`)
+	return s.String()
+}
+
+func diffLineClass(op string) string {
+	switch op {
+	case "-":
+		return "-dc-diff-delete"
+	case "+":
+		return "-dc-diff-insert"
+	default:
+		return ""
+	}
+}
+
+func renderUnifiedDiff(s *strings.Builder, lines []devcard.DiffLine) {
+	show := runtime.VisibleDiffLines(lines, diffContext)
+	skipped := false
+	for i, line := range lines {
+		if !show[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			s.WriteString(`<tr class="-dc-diff-skip"><td>…</td></tr>`)
+			skipped = false
+		}
+		op := line.Op
+		if op == "=" {
+			op = " "
+		}
+		fmt.Fprintf(s, `<tr class="%s"><td>%s %s</td></tr>`, diffLineClass(line.Op), op, html.EscapeString(line.Text))
+	}
+	if skipped {
+		s.WriteString(`<tr class="-dc-diff-skip"><td>…</td></tr>`)
+	}
+}
+
+// renderSideBySideDiff renders the lines in two columns. The deleted lines are
+// paired with the inserted lines that follow them.
+func renderSideBySideDiff(s *strings.Builder, lines []devcard.DiffLine) {
+	show := runtime.VisibleDiffLines(lines, diffContext)
+	cell := func(line *devcard.DiffLine) string {
+		if line == nil {
+			return `<td></td>`
+		}
+		return fmt.Sprintf(`<td class="%s">%s</td>`, diffLineClass(line.Op), html.EscapeString(line.Text))
+	}
+
+	skipped := false
+	for i := 0; i < len(lines); {
+		if !show[i] {
+			skipped = true
+			i++
+			continue
+		}
+		if skipped {
+			s.WriteString(`<tr class="-dc-diff-skip"><td>…</td><td>…</td></tr>`)
+			skipped = false
+		}
+		// The lines with unknown ops (DiffLine can be built by hand) are
+		// shown as unchanged.
+		if op := lines[i].Op; op != "-" && op != "+" {
+			fmt.Fprintf(s, `<tr>%s%s</tr>`, cell(&lines[i]), cell(&lines[i]))
+			i++
+			continue
+		}
+
+		var deleted, inserted []*devcard.DiffLine
+		for ; i < len(lines) && lines[i].Op == "-"; i++ {
+			deleted = append(deleted, &lines[i])
+		}
+		for ; i < len(lines) && lines[i].Op == "+"; i++ {
+			inserted = append(inserted, &lines[i])
+		}
+		for k := range max(len(deleted), len(inserted)) {
+			var left, right *devcard.DiffLine
+			if k < len(deleted) {
+				left = deleted[k]
+			}
+			if k < len(inserted) {
+				right = inserted[k]
+			}
+			fmt.Fprintf(s, `<tr>%s%s</tr>`, cell(left), cell(right))
+		}
+	}
+	if skipped {
+		s.WriteString(`<tr class="-dc-diff-skip"><td>…</td><td>…</td></tr>`)
+	}
+}
+
+func markdownDiff(b *devcard.DiffCell) string {
+	if len(b.Lines) == 0 {
+		return ""
+	}
+	header := ""
+	if b.LabelA != "" || b.LabelB != "" {
+		header = fmt.Sprintf("--- %s\n+++ %s\n", b.LabelA, b.LabelB)
+	}
+	return CodeBlock("diff", header+runtime.FormatDiff(b.Lines, diffContext))
+}
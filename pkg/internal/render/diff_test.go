@@ -0,0 +1,21 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/igorhub/devcard"
+)
+
+func TestRenderSideBySideDiffUnknownOp(t *testing.T) {
+	lines := []devcard.DiffLine{
+		{Op: "-", Text: "a"},
+		{Op: "?", Text: "b"},
+		{Op: "+", Text: "c"},
+	}
+	s := new(strings.Builder)
+	renderSideBySideDiff(s, lines)
+	if got := strings.Count(s.String(), "<tr>"); got != 3 {
+		t.Errorf("expected 3 rows, got %d:\n%s", got, s)
+	}
+}
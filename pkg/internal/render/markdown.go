@@ -0,0 +1,168 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/runtime"
+)
+
+// RenderMarkdown renders the cell as Markdown.
+func RenderMarkdown(source SourceFunc, b devcard.Cell) string {
+	switch b := b.(type) {
+	case *devcard.MarkdownCell:
+		return b.Text
+	case *devcard.HTMLCell:
+		return b.HTML
+	case *devcard.ErrorCell:
+		return markdownError(b.Title, b.Body)
+	case *devcard.MonospaceCell:
+		return CodeBlock(b.Highlighting, b.Text)
+	case *devcard.ValueCell:
+		return CodeBlock("go", strings.Join(b.Values, "\n\n"))
+	case *devcard.AnnotatedValueCell:
+		s := new(strings.Builder)
+		for i, v := range b.AnnotatedValues {
+			if i != 0 {
+				s.WriteString("\n\n")
+			}
+			if v.Annotation != "" {
+				s.WriteString(v.Annotation + "\n\n")
+			}
+			s.WriteString(CodeBlock("go", v.Value))
+		}
+		return s.String()
+	case *devcard.SourceCell:
+		return markdownSource(source, b)
+	case *devcard.ImageCell:
+		return MarkdownImage(b, func(path string) string { return path })
+	case *devcard.TableCell:
+		return markdownTable(b)
+	case *devcard.ChartCell:
+		return markdownChart(b)
+	case *devcard.DiffCell:
+		return markdownDiff(b)
+	case *devcard.CheckCell:
+		return markdownCheck(b)
+	case *devcard.InputCell:
+		return fmt.Sprintf("%s: `%s`", b.Name, b.Value)
+	case *devcard.JumpCell:
+		return ""
+	case *devcard.CustomCell:
+		return markdownError("CustomCell cannot be rendered", "CustomCell must be cast into one of the renderable cells.")
+	case nil:
+		return markdownError("Rendering error: trying to render nil", "")
+	default:
+		return markdownError(fmt.Sprintf("Rendering error: unknown type '%s'", b.Type()), "")
+	}
+}
+
+// CodeBlock returns text as a fenced Markdown code block.
+func CodeBlock(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimSuffix(text, "\n") + "\n" + fence
+}
+
+// MarkdownImage renders an ImageCell as Markdown, with its images located at
+// URLs returned by fileURL.
+func MarkdownImage(b *devcard.ImageCell, fileURL func(path string) string) string {
+	if b.Error != nil {
+		return markdownError(b.Error.Title, b.Error.Body)
+	}
+	s := new(strings.Builder)
+	for _, img := range b.Images {
+		fmt.Fprintf(s, "![%s](%s)\n", img.Annotation, fileURL(img.Path))
+	}
+	return s.String()
+}
+
+func markdownError(title, body string) string {
+	if title == "" && body == "" {
+		return ""
+	}
+	result := "**" + title + "**"
+	if body != "" {
+		result += "\n\n" + CodeBlock("", body)
+	}
+	return result
+}
+
+func markdownSource(source SourceFunc, b *devcard.SourceCell) string {
+	if len(b.Decls) == 0 {
+		return ""
+	}
+	if source == nil {
+		return markdownError("SourceCell error", "source code is not available")
+	}
+
+	var srcs []string
+	for _, decl := range b.Decls {
+		src, err := source(decl)
+		if err != nil {
+			return markdownError("SourceCell error", err.Error())
+		}
+		srcs = append(srcs, src)
+	}
+	return CodeBlock("go", strings.Join(srcs, "\n\n"))
+}
+
+func markdownTable(b *devcard.TableCell) string {
+	if len(b.Header) == 0 && len(b.Rows) == 0 {
+		return ""
+	}
+
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	row := func(values []string) string {
+		s := make([]string, len(values))
+		for i, v := range values {
+			s[i] = escape.Replace(v)
+		}
+		return "| " + strings.Join(s, " | ") + " |\n"
+	}
+
+	s := new(strings.Builder)
+	s.WriteString(row(b.Header))
+	s.WriteString(strings.Repeat("| --- ", len(b.Header)) + "|\n")
+	for _, r := range b.Rows {
+		s.WriteString(row(r))
+	}
+	return s.String()
+}
+
+// markdownChart renders the chart's data, as Markdown has no means to display
+// the chart itself.
+func markdownChart(b *devcard.ChartCell) string {
+	s := new(strings.Builder)
+	title := b.Title
+	if title == "" {
+		title = b.Kind + " chart"
+	}
+	fmt.Fprintf(s, "*%s*\n", title)
+	for i, series := range b.Series {
+		name := series.Name
+		if name == "" {
+			name = fmt.Sprintf("series %d", i+1)
+		}
+		if len(series.X) > 0 {
+			fmt.Fprintf(s, "\n- %s: x=%v, y=%v", name, series.X, series.Y)
+		} else {
+			fmt.Fprintf(s, "\n- %s: %v", name, series.Y)
+		}
+	}
+	return s.String()
+}
+
+func markdownCheck(b *devcard.CheckCell) string {
+	result := "✔ " + checkMessage(b)
+	if !b.Passed {
+		result = "✘ " + checkMessage(b)
+	}
+	if len(b.Diff) > 0 {
+		result += "\n\n" + CodeBlock("diff", runtime.FormatDiff(b.Diff, diffContext))
+	}
+	return result
+}
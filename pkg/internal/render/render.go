@@ -12,7 +12,17 @@ import (
 	"github.com/igorhub/devcard"
 )
 
-func RenderCell(highlighter *highlighter, b devcard.Cell) string {
+// SourceFunc returns the source of a declaration, such as "pkg.Func".
+type SourceFunc func(decl string) (string, error)
+
+// RenderCell renders the cell as HTML. fileURL returns the URL at which the
+// server serves the file (such as an image) produced by the devcard.
+//
+// subtreeURL returns the URL at which the server serves the children of a
+// node of the value's tree (see [RenderSubtree]), so that they're loaded only
+// when the node is expanded. If subtreeURL is nil, the trees are rendered in
+// full.
+func RenderCell(highlighter *highlighter, source SourceFunc, fileURL func(path string) string, subtreeURL func(devcard.ValueTree) string, b devcard.Cell) string {
 	switch b := b.(type) {
 	case *devcard.MarkdownCell:
 		return renderMarkdown(b)
@@ -23,14 +33,23 @@ func RenderCell(highlighter *highlighter, b devcard.Cell) string {
 	case *devcard.MonospaceCell:
 		return renderMonospace(highlighter, b)
 	case *devcard.ValueCell:
-		return renderValue(highlighter, b)
+		return renderValue(highlighter, b, subtreeURL)
 	case *devcard.AnnotatedValueCell:
 		return renderAnnotatedValue(highlighter, b)
 	case *devcard.SourceCell:
-		return renderError("Not implemented", "cannot render SourceCell - not implemented")
-		// return renderSource(project, highlighter, b)
+		return renderSource(highlighter, source, b)
 	case *devcard.ImageCell:
-		return renderImage(b)
+		return RenderImage(b, fileURL)
+	case *devcard.TableCell:
+		return renderTable(b)
+	case *devcard.ChartCell:
+		return renderChart(b)
+	case *devcard.DiffCell:
+		return renderDiff(b)
+	case *devcard.CheckCell:
+		return renderCheck(b)
+	case *devcard.InputCell:
+		return renderInput(b)
 	case *devcard.JumpCell:
 		return ""
 	case *devcard.CustomCell:
@@ -87,36 +106,126 @@ func renderMonospace(highlighter *highlighter, b *devcard.MonospaceCell) string
 	return result
 }
 
-// // TODO: implement
-//
-//	func renderSource(project *project.Project, highlighter *highlighter, b *devcard.SourceCell) string {
-//		if len(b.Decls) == 0 {
-//			return ""
-//		}
-//
-//		s := strings.Builder{}
-//		for i, decl := range b.Decls {
-//			if i != 0 {
-//				s.WriteString("\n\n")
-//			}
-//			src, err := project.Source(decl)
-//			if err != nil {
-//				return renderError("SourceCell error", err.Error())
-//			}
-//			s.WriteString(src)
-//		}
-//		return renderMonospace(highlighter, devcard.NewMonospaceCell(s.String(), devcard.WithHighlighting("go")))
-//	}
-
-func renderValue(highlighter *highlighter, b *devcard.ValueCell) string {
-	values := strings.Join(b.Values, "\n\n")
-	result, err := highlighter.Highlight(values, "go")
+func renderSource(highlighter *highlighter, source SourceFunc, b *devcard.SourceCell) string {
+	if len(b.Decls) == 0 {
+		return ""
+	}
+	if source == nil {
+		return renderError("SourceCell error", "source code is not available")
+	}
+
+	s := strings.Builder{}
+	for i, decl := range b.Decls {
+		if i != 0 {
+			s.WriteString("\n\n")
+		}
+		src, err := source(decl)
+		if err != nil {
+			return renderError("SourceCell error", err.Error())
+		}
+		s.WriteString(src)
+	}
+	return renderMonospace(highlighter, devcard.NewMonospaceCell(s.String(), devcard.WithHighlighting("go")))
+}
+
+func renderValue(highlighter *highlighter, b *devcard.ValueCell, subtreeURL func(devcard.ValueTree) string) string {
+	if len(b.Trees) != len(b.Values) {
+		return renderValueText(highlighter, b.Values)
+	}
+
+	// The composite values are rendered as trees; the scalars, as well as the
+	// empty slices, maps, and structs, are rendered as text.
+	s := new(strings.Builder)
+	var text []string
+	for i, tree := range b.Trees {
+		if len(tree.Children) == 0 {
+			text = append(text, b.Values[i])
+			continue
+		}
+		if len(text) > 0 {
+			s.WriteString(renderValueText(highlighter, text))
+			text = nil
+		}
+		s.WriteString(`<div class="-dc-tree">`)
+		renderValueTree(s, tree, 0, subtreeURL)
+		s.WriteString(`</div>`)
+	}
+	if len(text) > 0 {
+		s.WriteString(renderValueText(highlighter, text))
+	}
+	return s.String()
+}
+
+func renderValueText(highlighter *highlighter, values []string) string {
+	text := strings.Join(values, "\n\n")
+	result, err := highlighter.Highlight(text, "go")
 	if err != nil {
-		result = "<pre><code>" + html.EscapeString(values) + "</code></pre>"
+		result = "<pre><code>" + html.EscapeString(text) + "</code></pre>"
 	}
 	return result
 }
 
+// lazyDepth is the depth of the tree's nodes starting from which the nodes'
+// children are loaded only when the node is expanded.
+const lazyDepth = 1
+
+// renderValueTree renders the tree as nested <details> elements. If
+// subtreeURL isn't nil, the children of the nodes at lazyDepth and deeper are
+// omitted; they're loaded from the URL when the node is expanded.
+func renderValueTree(s *strings.Builder, t devcard.ValueTree, depth int, subtreeURL func(devcard.ValueTree) string) {
+	label := new(strings.Builder)
+	if t.Key != "" {
+		fmt.Fprintf(label, `<span class="-dc-tree-key">%s:</span> `, html.EscapeString(t.Key))
+	}
+	fmt.Fprintf(label, `<span class="-dc-tree-type">%s</span>`, html.EscapeString(t.Type))
+	switch t.Kind {
+	case "slice", "array", "map":
+		if t.Text == "" {
+			fmt.Fprintf(label, ` <span class="-dc-tree-len">len %d</span>`, t.Len)
+		}
+	}
+	if t.Text != "" {
+		fmt.Fprintf(label, ` <span class="-dc-tree-text">%s</span>`, html.EscapeString(t.Text))
+	}
+	if t.Truncated {
+		label.WriteString(` <span class="-dc-tree-text">…</span>`)
+	}
+
+	switch {
+	case len(t.Children) == 0:
+		fmt.Fprintf(s, `<div class="-dc-tree-leaf">%s</div>`, label)
+		return
+	case depth == 0:
+		s.WriteString(`<details open>`)
+	case depth >= lazyDepth && subtreeURL != nil:
+		fmt.Fprintf(s, `<details data-subtree="%s"><summary>%s</summary></details>`, html.EscapeString(subtreeURL(t)), label)
+		return
+	default:
+		s.WriteString(`<details>`)
+	}
+	fmt.Fprintf(s, `<summary>%s</summary>`, label)
+	renderValueChildren(s, t, depth, subtreeURL)
+	s.WriteString(`</details>`)
+}
+
+func renderValueChildren(s *strings.Builder, t devcard.ValueTree, depth int, subtreeURL func(devcard.ValueTree) string) {
+	for _, child := range t.Children {
+		renderValueTree(s, child, depth+1, subtreeURL)
+	}
+	if t.More > 0 {
+		fmt.Fprintf(s, `<div class="-dc-tree-leaf"><span class="-dc-tree-text">… %d more</span></div>`, t.More)
+	}
+}
+
+// RenderSubtree renders the children of the tree's node, which are loaded
+// when the node is expanded. The children's own children are loaded from the
+// URLs returned by subtreeURL in their turn.
+func RenderSubtree(t devcard.ValueTree, subtreeURL func(devcard.ValueTree) string) string {
+	s := new(strings.Builder)
+	renderValueChildren(s, t, lazyDepth, subtreeURL)
+	return s.String()
+}
+
 func renderAnnotatedValue(highlighter *highlighter, b *devcard.AnnotatedValueCell) string {
 	if len(b.AnnotatedValues) == 0 {
 		return ""
@@ -141,14 +250,16 @@ func renderAnnotatedValue(highlighter *highlighter, b *devcard.AnnotatedValueCel
 	return result
 }
 
-func renderImage(b *devcard.ImageCell) string {
+// RenderImage renders an ImageCell, with its images located at URLs
+// returned by fileURL.
+func RenderImage(b *devcard.ImageCell, fileURL func(path string) string) string {
 	if b.Error != nil {
 		return renderError(b.Error.Title, b.Error.Body)
 	}
 
 	f := `<figure>
   <img
-  src="/file?path=%s"
+  src="%s"
   alt="%s"/>
   <figcaption>%s</figcaption>
 </figure>
@@ -156,7 +267,95 @@ func renderImage(b *devcard.ImageCell) string {
 
 	s := &strings.Builder{}
 	for _, img := range b.Images {
-		fmt.Fprintf(s, f, url.QueryEscape(img.Path), img.Path, img.Annotation)
+		fmt.Fprintf(s, f, fileURL(img.Path), img.Path, img.Annotation)
 	}
 	return s.String()
 }
+
+func renderTable(b *devcard.TableCell) string {
+	if len(b.Header) == 0 && len(b.Rows) == 0 {
+		return ""
+	}
+
+	s := new(strings.Builder)
+	s.WriteString(`<table class="-dc-table"><thead><tr>`)
+	for _, column := range b.Header {
+		fmt.Fprintf(s, `<th onclick="devcardsSortTable(this)">%s</th>`, html.EscapeString(column))
+	}
+	s.WriteString(`</tr></thead><tbody>`)
+	for _, row := range b.Rows {
+		s.WriteString("<tr>")
+		for _, value := range row {
+			fmt.Fprintf(s, "<td>%s</td>", html.EscapeString(value))
+		}
+		s.WriteString("</tr>")
+	}
+	s.WriteString("</tbody></table>")
+	return s.String()
+}
+
+func renderInput(b *devcard.InputCell) string {
+	onChange := func(value string) string {
+		action := fmt.Sprintf("@post('/devcards/input?name=%s&value=' + encodeURIComponent(%s))", url.QueryEscape(b.Name), value)
+		return fmt.Sprintf(`data-on-change="%s"`, html.EscapeString(action))
+	}
+
+	s := new(strings.Builder)
+	fmt.Fprintf(s, `<label class="-dc-input">%s `, html.EscapeString(b.Name))
+	switch b.Kind {
+	case devcard.InputSlider:
+		fmt.Fprintf(s, `<input type="range" min="%d" max="%d" value="%s" oninput="this.nextElementSibling.value = this.value" %s>`,
+			b.Min, b.Max, html.EscapeString(b.Value), onChange("evt.target.value"))
+		fmt.Fprintf(s, `<output>%s</output>`, html.EscapeString(b.Value))
+	case devcard.InputCheckbox:
+		checked := ""
+		if b.Value == "true" {
+			checked = " checked"
+		}
+		fmt.Fprintf(s, `<input type="checkbox"%s %s>`, checked, onChange("evt.target.checked"))
+	case devcard.InputSelect:
+		fmt.Fprintf(s, `<select %s>`, onChange("evt.target.value"))
+		for _, option := range b.Options {
+			selected := ""
+			if option == b.Value {
+				selected = " selected"
+			}
+			fmt.Fprintf(s, `<option%s>%s</option>`, selected, html.EscapeString(option))
+		}
+		s.WriteString(`</select>`)
+	case devcard.InputText:
+		fmt.Fprintf(s, `<input type="text" value="%s" %s>`, html.EscapeString(b.Value), onChange("evt.target.value"))
+	default:
+		return renderError("InputCell error", fmt.Sprintf("unknown kind of input: %q", b.Kind))
+	}
+	s.WriteString(`</label>`)
+	return s.String()
+}
+
+func renderCheck(b *devcard.CheckCell) string {
+	class, mark := "-dc-check-passed", "✔"
+	if !b.Passed {
+		class, mark = "-dc-check-failed", "✘"
+	}
+	s := new(strings.Builder)
+	fmt.Fprintf(s, `<div class="-dc-check %s">%s %s</div>`, class, mark, html.EscapeString(checkMessage(b)))
+	if len(b.Diff) > 0 {
+		s.WriteString(`<table class="-dc-diff"><tbody>`)
+		renderUnifiedDiff(s, b.Diff)
+		s.WriteString(`</tbody></table>`)
+	}
+	return s.String()
+}
+
+// checkMessage returns the check's message, or, if it's empty, the check's
+// status.
+func checkMessage(b *devcard.CheckCell) string {
+	switch {
+	case b.Message != "":
+		return b.Message
+	case b.Passed:
+		return "passed"
+	default:
+		return "failed"
+	}
+}
@@ -0,0 +1,282 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BuildCache builds the generated main packages of the fork and keeps the
+// compiled binaries, so that devcards from the same package, or a devcard
+// re-run without changes in the source code, don't need to be compiled again.
+//
+// The binaries are keyed by the hash of the fork's dirs they're built from
+// (see [BuildCache.SetSources]). Only the latest binary is kept for each
+// package (and for each package's test binary); the previous ones are removed
+// once they stop running.
+//
+// It's safe for concurrent use.
+type BuildCache struct {
+	forkDir string
+	dir     string
+
+	lock    sync.Mutex
+	builds  map[target]*build
+	stale   []*build
+	sources Sources
+}
+
+// Sources describe the dirs of the fork the binaries are built from.
+type Sources struct {
+	// Deps are the dirs (and files) each main package is built from, indexed
+	// by the main package's dir: the dir itself, the dirs of the project's
+	// packages it imports, directly or transitively, and the files of their
+	// modules.
+	Deps map[string][]string
+
+	// Packages are the dirs of all the project's packages. They're skipped
+	// when they're nested in the dirs of Deps, as they're separate packages.
+	Packages map[string]bool
+}
+
+// target identifies the package to build.
+type target struct {
+	dir  string
+	test bool
+}
+
+type build struct {
+	key  string
+	path string
+	err  error
+	done chan struct{}
+
+	// users is the number of the binary's users (guarded by the cache's
+	// lock). The binary isn't removed while it's in use.
+	users   int
+	removed bool
+}
+
+// NewBuildCache creates a cache for the binaries built from forkDir.
+func NewBuildCache(forkDir string) (*BuildCache, error) {
+	dir, err := os.MkdirTemp("", "devcards-builds-")
+	if err != nil {
+		return nil, fmt.Errorf("new build cache: %w", err)
+	}
+	return &BuildCache{forkDir: forkDir, dir: dir, builds: map[target]*build{}}, nil
+}
+
+// Close deletes the cached binaries.
+func (c *BuildCache) Close() error {
+	return os.RemoveAll(c.dir)
+}
+
+// SetSources sets the dirs the binaries are built from. Until it's called
+// for a main package, the package is considered to be built from the entire
+// fork.
+func (c *BuildCache) SetSources(s Sources) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.sources = s
+}
+
+// Build returns the path to the binary built from the main package in
+// mainDir. The package is compiled only if its sources have changed since the
+// binary was built.
+//
+// The caller must call release once it's done running the binary.
+func (c *BuildCache) Build(ctx context.Context, mainDir string) (path string, release func(), err error) {
+	return c.build(ctx, target{dir: mainDir})
+}
+
+// BuildTest is like [BuildCache.Build], but it builds the test binary of the
+// package in dir.
+func (c *BuildCache) BuildTest(ctx context.Context, dir string) (path string, release func(), err error) {
+	return c.build(ctx, target{dir: dir, test: true})
+}
+
+func (c *BuildCache) build(ctx context.Context, t target) (string, func(), error) {
+	key, err := c.hash(t.dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("build: %w", err)
+	}
+
+	for {
+		b, owner := c.lookup(t, key)
+		if owner {
+			b.path, b.err = c.compile(ctx, t, key)
+			if b.err != nil {
+				c.forget(t, b)
+			}
+			close(b.done)
+			c.cleanup()
+		} else {
+			select {
+			case <-b.done:
+			case <-ctx.Done():
+				return "", nil, ctx.Err()
+			}
+		}
+
+		switch {
+		case b.err == nil:
+			if c.acquire(b) {
+				return b.path, func() { c.release(b) }, nil
+			}
+			// The binary has been replaced and removed; try again.
+		case owner || !errors.Is(b.err, context.Canceled):
+			return "", nil, b.err
+		}
+		// The runner that started the build has been stopped; try again.
+	}
+}
+
+// lookup returns the build of the target with the given key. If there's no
+// such build, it's created, and owner is true: the caller is responsible for
+// compiling it.
+func (c *BuildCache) lookup(t target, key string) (b *build, owner bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if b := c.builds[t]; b != nil && b.key == key {
+		return b, false
+	}
+	if old := c.builds[t]; old != nil {
+		c.stale = append(c.stale, old)
+	}
+	b = &build{key: key, done: make(chan struct{})}
+	c.builds[t] = b
+	return b, true
+}
+
+func (c *BuildCache) forget(t target, b *build) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.builds[t] == b {
+		delete(c.builds, t)
+	}
+}
+
+// acquire marks the binary as used. It returns false if the binary has been
+// removed already.
+func (c *BuildCache) acquire(b *build) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if b.removed {
+		return false
+	}
+	b.users++
+	return true
+}
+
+func (c *BuildCache) release(b *build) {
+	c.lock.Lock()
+	b.users--
+	c.lock.Unlock()
+	c.cleanup()
+}
+
+// cleanup removes the binaries of the stale builds that are no longer used.
+func (c *BuildCache) cleanup() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.stale = slices.DeleteFunc(c.stale, func(b *build) bool {
+		select {
+		case <-b.done:
+		default:
+			// Still compiling.
+			return false
+		}
+		if b.users > 0 {
+			return false
+		}
+		if b.path != "" {
+			os.Remove(b.path)
+		}
+		b.removed = true
+		return true
+	})
+}
+
+func (c *BuildCache) compile(ctx context.Context, t target, key string) (string, error) {
+	// The packages with the same base name are told apart by the hash of
+	// their dirs.
+	dirHash := strconv.FormatUint(maphash.String(buildSeed, t.dir), 36)
+	path := filepath.Join(c.dir, filepath.Base(t.dir)+"_"+dirHash+"_"+key)
+	args := []string{"build", "-tags", "devcard", "-o", path, "."}
+	if t.test {
+		path += ".test"
+		args = []string{"test", "-c", "-tags", "devcard", "-o", path, "."}
+	}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = t.dir
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("go %s: %w\n%s", args[0], err, output)
+	}
+	return path, nil
+}
+
+var buildSeed = maphash.MakeSeed()
+
+// hash returns the hash of the sources of the main package in mainDir (see
+// [Sources]). The files are identified by their paths, sizes and
+// modification times, so reading their content isn't required.
+func (c *BuildCache) hash(mainDir string) (string, error) {
+	c.lock.Lock()
+	deps, ok := c.sources.Deps[mainDir]
+	packages := c.sources.Packages
+	c.lock.Unlock()
+	if !ok {
+		deps, packages = []string{c.forkDir}, nil
+	}
+
+	var h maphash.Hash
+	h.SetSeed(buildSeed)
+	for _, root := range deps {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				// The file has been removed while we were walking the fork.
+				return nil
+			case err != nil:
+				return err
+			case strings.HasPrefix(d.Name(), "_transient"):
+				// Transient dirs are created by the runners and have nothing to
+				// do with the source code.
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			case d.IsDir() && path != root && packages != nil && (packages[path] || strings.HasPrefix(d.Name(), "gen_main_")):
+				// Nested packages are hashed only if they're among the deps.
+				return fs.SkipDir
+			case d.IsDir():
+				return nil
+			}
+			info, err := d.Info()
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			fmt.Fprintf(&h, "%s\x00%d\x00%d\x00", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return strconv.FormatUint(h.Sum64(), 36), nil
+}
@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildCacheCleanup(t *testing.T) {
+	c, err := NewBuildCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// The old binary is replaced while it's running.
+	old, _ := c.lookup(target{dir: "foo"}, "1")
+	old.path = filepath.Join(c.dir, "foo_1")
+	os.WriteFile(old.path, nil, 0700)
+	close(old.done)
+	if !c.acquire(old) {
+		t.Fatal("the binary is removed before it's replaced")
+	}
+	c.lookup(target{dir: "foo"}, "2")
+
+	c.cleanup()
+	if _, err := os.Stat(old.path); err != nil {
+		t.Errorf("the binary is removed while it's in use: %v", err)
+	}
+	c.release(old)
+	if _, err := os.Stat(old.path); !os.IsNotExist(err) {
+		t.Errorf("the stale binary isn't removed: %v", err)
+	}
+	if c.acquire(old) {
+		t.Errorf("the removed binary is acquired")
+	}
+}
@@ -1,5 +1,11 @@
 package runner
 
+import (
+	"slices"
+
+	"github.com/igorhub/devcard"
+)
+
 type UpdateMessage interface{ updateMessage() }
 
 type Card struct {
@@ -25,11 +31,40 @@ func (c *Card) addCell(cell Cell) {
 type Cell struct {
 	Id      string
 	Content string
+	Raw     devcard.Cell
+
+	// Path (relative to the project dir) and Line locate the code that
+	// created the cell. Path is empty if the location is unknown.
+	Path string
+	Line int
+}
+
+// Result is the complete outcome of a single run of the devcard. It's sent
+// once the devcard finishes running.
+type Result struct {
+	Title     string
+	CSS       CSS
+	Cells     []Cell
+	Stdout    string
+	Stderr    string
+	BuildTime string
+	RunTime   string
+	Errors    []Error
+}
+
+func (r *Result) addCell(cell Cell) {
+	i := slices.IndexFunc(r.Cells, func(c Cell) bool { return c.Id == cell.Id })
+	if i == -1 {
+		r.Cells = append(r.Cells, cell)
+	} else {
+		r.Cells[i] = cell
+	}
 }
 
 type Meta struct {
 	BuildTime string
 	RunTime   string
+	Finished  bool
 }
 
 type Error struct {
@@ -57,6 +92,7 @@ type Stderr struct {
 type Heartbeat struct{}
 
 func (Card) updateMessage()      {}
+func (Result) updateMessage()    {}
 func (Cell) updateMessage()      {}
 func (Meta) updateMessage()      {}
 func (Error) updateMessage()     {}
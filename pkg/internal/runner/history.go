@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/runtime"
+)
+
+// DefaultHistorySize is the number of runs kept in the history of each
+// devcard, unless configured otherwise.
+const DefaultHistorySize = 10
+
+// History keeps the results of the latest runs of the project's devcards.
+//
+// It's safe for concurrent use.
+type History struct {
+	size int
+
+	lock sync.Mutex
+	runs map[string][]Run // by the devcard's name, the latest run goes first
+}
+
+// Run is a record of a single run of the devcard.
+type Run struct {
+	Time   time.Time
+	Result Result
+
+	// Params are the URL-encoded parameters of the devcard (see
+	// [devcard.Devcard.Param]) used in the run.
+	Params string
+
+	// Diff is a readable cell-by-cell description of the differences from the
+	// previous run with the same parameters. It's empty if the output hasn't
+	// changed, or if there's no such run.
+	Diff string
+
+	// Changed are the ids of the cells that differ from the previous run with
+	// the same parameters.
+	Changed []string
+
+	// Compared is true if there's a previous run with the same parameters.
+	Compared bool
+
+	// The snapshots of the whole devcard, and of each of its cells. They're
+	// made right after the run, while the images still exist.
+	snapshot []byte
+	cells    [][]byte
+}
+
+// Changes is sent once the devcard finishes running. It lists the cells that
+// have changed since the previous run.
+type Changes struct {
+	Cells []Cell
+}
+
+func (Changes) updateMessage() {}
+
+// NewHistory creates a history keeping size runs for each devcard.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{size: size, runs: map[string][]Run{}}
+}
+
+// Runs returns the runs of the devcard, the latest run first.
+func (h *History) Runs(devcardName string) []Run {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return slices.Clone(h.runs[devcardName])
+}
+
+// add records the result of the devcard's run, and compares it against the
+// previous run with the same parameters. The runs with different parameters
+// aren't compared, as their output is expected to differ.
+func (h *History) add(devcardName, params string, result Result) Run {
+	run := Run{Time: time.Now(), Result: result, Params: params}
+	title := result.Title
+	if title == "" {
+		title = "Untitled devcard"
+	}
+	cells := make([]devcard.Cell, len(result.Cells))
+	for i, cell := range result.Cells {
+		cells[i] = cell.Raw
+		s, _ := runtime.Snapshot("", []devcard.Cell{cell.Raw})
+		run.cells = append(run.cells, s)
+	}
+	run.snapshot, _ = runtime.Snapshot(title, cells)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	runs := h.runs[devcardName]
+	if i := slices.IndexFunc(runs, func(r Run) bool { return r.Params == params }); i != -1 {
+		prev := runs[i]
+		run.Compared = true
+		if prev.snapshot != nil && run.snapshot != nil {
+			run.Diff = runtime.DiffSnapshots(prev.snapshot, run.snapshot)
+		}
+		for i, cell := range result.Cells {
+			if i >= len(prev.cells) || prev.cells[i] == nil || !bytes.Equal(prev.cells[i], run.cells[i]) {
+				run.Changed = append(run.Changed, cell.Id)
+			}
+		}
+	}
+	runs = append([]Run{run}, runs...)
+	h.runs[devcardName] = runs[:min(len(runs), h.size)]
+	return run
+}
@@ -23,10 +23,10 @@ const (
 	PipeStderr = "Stderr"
 )
 
-// Run uses "go run" to produce and return a devcard.
+// Run builds the devcard (unless it's already built) and runs it.
 //
 // If errors occur, they're written into devcard.Error field of the devcard.
-func (r *Runner) run(ctx context.Context, updates chan<- any) {
+func (r *Runner) run(ctx context.Context, params string, updates chan<- any) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -52,12 +52,12 @@ func (r *Runner) run(ctx context.Context, updates chan<- any) {
 		defer conn.Close()
 		updates <- evBuilt{}
 
+		wg.Add(1)
 		go func() {
-			wg.Add(1)
 			defer wg.Done()
 			r := bufio.NewReader(conn)
 			for {
-				s, err := r.ReadString('\n')
+				s, err := readMessage(r, maxMessageSize)
 				if err != nil && errors.Is(err, io.EOF) {
 					return
 				} else if err != nil {
@@ -77,8 +77,25 @@ func (r *Runner) run(ctx context.Context, updates chan<- any) {
 		conn.Close()
 	}()
 
-	cmd := exec.CommandContext(ctx, "go", "run", "-tags", "devcard", ".", r.dir, r.transientDir, r.cardMeta.Name, listener.Addr().String())
-	cmd.Dir = filepath.Join(r.dir, file.DevcardMainDir(r.cardMeta))
+	mainDir := filepath.Join(r.env.Dir, file.DevcardMainDir(r.cardMeta))
+	build, args := r.env.Builds.Build, []string{}
+	if r.cardMeta.Test {
+		// Devcards from test files are run by the generated test function.
+		build, args = r.env.Builds.BuildTest, []string{"-test.run=^Test_devcardMain$", "--"}
+	}
+	binary, release, err := build(ctx, mainDir)
+	if err != nil {
+		updates <- evBuilt{}
+		if ctx.Err() == nil {
+			updates <- Error{Title: "Build failure", Err: err}
+		}
+		return
+	}
+	defer release()
+
+	args = append(args, r.env.Dir, r.transientDir, r.cardMeta.Name, listener.Addr().String(), params)
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = mainDir
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -112,7 +129,7 @@ func (r *Runner) run(ctx context.Context, updates chan<- any) {
 
 	err = cmd.Run()
 	if err != nil {
-		err := fmt.Errorf("go run: %w", err)
+		err := fmt.Errorf("%s: %w", r.cardMeta.Name, err)
 		updates <- Error{Title: "Execution failure", Err: err}
 	}
 
@@ -165,7 +182,33 @@ func readFromPipe(pipe io.Reader, pipeName string) <-chan UpdateMessage {
 	return updates
 }
 
+// maxMessageSize is the maximum size of a message from the devcard. Larger
+// messages are rejected, as they would freeze the browser anyway.
+const maxMessageSize = 16 << 20
+
+// readMessage reads a newline-terminated message. Only the first limit+1
+// bytes of the message are kept in memory; the rest is discarded.
+func readMessage(r *bufio.Reader, limit int) (string, error) {
+	var msg []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(msg) <= limit {
+			msg = append(msg, chunk[:min(len(chunk), limit+1-len(msg))]...)
+		}
+		if err != bufio.ErrBufferFull {
+			return string(msg), err
+		}
+	}
+}
+
 func unmarshalDevcardMessage(msg string) UpdateMessage {
+	if len(msg) > maxMessageSize {
+		return Error{
+			Title: "The devcard's message is too large",
+			Err:   fmt.Errorf("the message exceeds the limit of %d bytes; consider devcard.WithLimit to truncate large values", maxMessageSize),
+		}
+	}
+
 	x := struct {
 		MsgType string `json:"msg_type"`
 
@@ -173,6 +216,8 @@ func unmarshalDevcardMessage(msg string) UpdateMessage {
 		Id       string
 		CellType string `json:"cell_type"`
 		Cell     json.RawMessage
+		File     string
+		Line     int
 
 		// Other types
 		Title string
@@ -192,7 +237,7 @@ func unmarshalDevcardMessage(msg string) UpdateMessage {
 			err = fmt.Errorf("error: %s\n\ncell type: %s\n\ncell: %s", err, x.CellType, string(x.Cell))
 			return Error{Title: "Failed to decode a cell from the devcard", Err: err}
 		}
-		return evCell{Id: x.Id, Cell: cell}
+		return evCell{Id: x.Id, Cell: cell, File: x.File, Line: x.Line}
 
 	case devcard.MessageTypeTitle:
 		if x.Title != "" {
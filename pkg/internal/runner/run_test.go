@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadMessage(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	input := "short\n" + long + "\n" + "unterminated"
+	// The reader's buffer is smaller than the long message, so the message is
+	// read in chunks.
+	r := bufio.NewReaderSize(strings.NewReader(input), 16)
+
+	tests := []struct {
+		want string
+		err  error
+	}{
+		{"short\n", nil},
+		{long[:51], nil},
+		{"unterminated", io.EOF},
+	}
+	for i, test := range tests {
+		got, err := readMessage(r, 50)
+		if got != test.want || err != test.err {
+			t.Errorf("message %d: got %q, %v; want %q, %v", i, got, err, test.want, test.err)
+		}
+	}
+}
+
+func TestUnmarshalDevcardMessageTooLarge(t *testing.T) {
+	msg := unmarshalDevcardMessage(strings.Repeat("x", maxMessageSize+1))
+	if e, ok := msg.(Error); !ok || e.Title != "The devcard's message is too large" {
+		t.Errorf("got %#v, want an error", msg)
+	}
+}
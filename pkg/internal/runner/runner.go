@@ -4,11 +4,18 @@ package runner
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
 	"math/rand/v2"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/igorhub/devcard"
@@ -16,48 +23,96 @@ import (
 	"github.com/igorhub/devcard/pkg/internal/render"
 )
 
+// Env is the environment shared by the runners of a project.
+type Env struct {
+	// Dir is the directory of the project's fork.
+	Dir string
+
+	// Source returns the source code of the project's declarations.
+	Source render.SourceFunc
+
+	// Builds caches the compiled devcards.
+	Builds *BuildCache
+
+	// Timeout is the maximum duration of a devcard's run. Zero means no limit.
+	Timeout time.Duration
+
+	// History records the results of the runs.
+	History *History
+
+	// RunTests enables running the tests of the devcard's package alongside
+	// the devcard.
+	RunTests bool
+}
+
 type Runner struct {
 	ch           chan any // maybe not
-	dir          string
+	env          Env
 	transientDir string
 	cardMeta     devcard.DevcardMeta
 	cfg          *config.Config
+	params       url.Values
 
 	start, build int
 
+	// tests is the status of the last complete run of the tests. It's reset
+	// when the project changes.
+	tests *Tests
+
+	// subtrees are the nodes of the values' trees whose children are rendered
+	// when the node is expanded (see [Runner.Subtree]), by their ids. They're
+	// reset on each run.
+	subtreesLock sync.Mutex
+	subtrees     map[string]devcard.ValueTree
+	lastSubtree  int
+
 	Id          string
 	DevcardName string
-	Error       error
-	Updates     chan any
+
+	// Token identifies the runner in the URLs of the files produced by its
+	// devcard. Unlike Id, it's never sent to the client as a signal.
+	Token string
+
+	Error   error
+	Updates chan any
 }
 
 func StartFakeRunner(cfg *config.Config, err error) *Runner {
 	r := &Runner{
 		cfg:     cfg,
 		Id:      "r" + strconv.Itoa(rand.Int()),
+		Token:   newToken(),
 		ch:      make(chan any, 1024),
 		Updates: make(chan any, 8),
 		Error:   err,
 	}
 	r.Updates <- Card{}
 	r.Updates <- makeError(err)
+	r.Updates <- Result{Errors: []Error{makeError(err)}}
 	// r.Updates <- Cell{"-dc-cell-error", "restart the server?"}
 	return r
 }
 
-func Start(cfg *config.Config, dir string, meta devcard.DevcardMeta) *Runner {
+// Start starts a runner for the devcard. The params are the initial values of
+// the devcard's parameters (see [devcard.Devcard.Param]).
+func Start(cfg *config.Config, env Env, meta devcard.DevcardMeta, params url.Values) *Runner {
+	if params == nil {
+		params = url.Values{}
+	}
 	r := &Runner{
 		cfg:          cfg,
+		env:          env,
 		Id:           "r" + strconv.Itoa(rand.Int()),
+		Token:        newToken(),
 		ch:           make(chan any, 1024),
-		dir:          dir,
-		transientDir: filepath.Join(dir, "_transient"+strconv.Itoa(rand.Int())),
+		transientDir: filepath.Join(env.Dir, "_transient"+strconv.Itoa(rand.Int())),
 		cardMeta:     meta,
+		params:       maps.Clone(params),
 		DevcardName:  meta.Name,
 		Updates:      make(chan any, 1024),
 	}
 	if err := os.Mkdir(r.transientDir, 0700); err != nil {
-		r.Error = fmt.Errorf("unable to start runner in %s: %w", dir, err)
+		r.Error = fmt.Errorf("unable to start runner in %s: %w", env.Dir, err)
 	}
 
 	go r.runEventLoop()
@@ -69,6 +124,80 @@ func (r *Runner) Restart(cfg *config.Config, err error) {
 	r.ch <- evRestart{err}
 }
 
+// SetParam sets the value of the devcard's input and re-runs the devcard.
+func (r *Runner) SetParam(name, value string) {
+	r.ch <- evSetParam{name, value}
+}
+
+// Interrupt stops the running devcard.
+func (r *Runner) Interrupt() {
+	r.ch <- evInterrupt{}
+}
+
+// Rerun runs the devcard again.
+func (r *Runner) Rerun() {
+	r.ch <- evRerun{}
+}
+
+// File returns the path to the file produced by the runner's devcard. name is
+// the path relative to the runner's transient dir, as in the URL returned by
+// fileURL.
+func (r *Runner) File(name string) (string, bool) {
+	if r.transientDir == "" || !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", false
+	}
+	return filepath.Join(r.transientDir, filepath.FromSlash(name)), true
+}
+
+// fileURL returns the URL at which the server serves the file produced by the
+// runner's devcard. The file must be located in the runner's transient dir.
+func (r *Runner) fileURL(path string) string {
+	name, err := filepath.Rel(r.transientDir, path)
+	if err != nil || !filepath.IsLocal(name) {
+		return ""
+	}
+	return "/file?runner=" + r.Token + "&name=" + url.QueryEscape(filepath.ToSlash(name))
+}
+
+// subtreeURL returns the URL at which the server serves the rendered children
+// of the tree's node.
+func (r *Runner) subtreeURL(t devcard.ValueTree) string {
+	r.subtreesLock.Lock()
+	defer r.subtreesLock.Unlock()
+	if r.subtrees == nil {
+		r.subtrees = map[string]devcard.ValueTree{}
+	}
+	r.lastSubtree++
+	id := strconv.Itoa(r.lastSubtree)
+	r.subtrees[id] = t
+	return "/tree?runner=" + r.Token + "&id=" + id
+}
+
+// Subtree returns the rendered children of the node of a value's tree, as
+// identified by the URL returned by subtreeURL. It returns false if there's no
+// such node in the devcard's latest run.
+func (r *Runner) Subtree(id string) (string, bool) {
+	r.subtreesLock.Lock()
+	t, ok := r.subtrees[id]
+	r.subtreesLock.Unlock()
+	if !ok {
+		return "", false
+	}
+	return render.RenderSubtree(t, r.subtreeURL), true
+}
+
+func (r *Runner) resetSubtrees() {
+	r.subtreesLock.Lock()
+	defer r.subtreesLock.Unlock()
+	r.subtrees = nil
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func (r *Runner) Shutdown() {
 	r.ch <- evClose{}
 }
@@ -86,22 +215,42 @@ func (r *Runner) runEventLoop() {
 		var started, built, finished int64
 		started = time.Now().UnixMilli()
 		highlighter := render.NewHighlighter(r.cfg.Appearance.CodeHighlighting)
+		result := &Result{}
+		r.resetSubtrees()
+		running, interrupted := r.Error == nil, false
+		interrupt := func(e Error) {
+			if !running || interrupted {
+				return
+			}
+			interrupted = true
+			cancel()
+			result.Errors = append(result.Errors, e)
+			r.ch <- evFlush{e}
+		}
 
 		if r.Error == nil {
 			ch := r.ch
+			params := r.params.Encode()
 			go func() {
 				ch <- Heartbeat{}
 				ch <- CSS{Values: []string{devcard.CSSFromServer}}
-				r.run(ctx, ch)
+				r.run(ctx, params, ch)
 				ch <- evFlush{}
 				ch <- evFinish{}
+				ch <- evDone{}
 			}()
 			go func() {
 				time.Sleep(1000 * time.Millisecond)
 				ch <- evFlush{}
 			}()
+			if r.env.RunTests && r.tests != nil {
+				ch <- *r.tests
+			} else if r.env.RunTests {
+				go r.runTests(ctx, ch)
+			}
 		} else {
 			r.ch <- makeError(r.Error)
+			r.ch <- evDone{}
 		}
 	innerLoop:
 		for e := range r.ch {
@@ -111,8 +260,23 @@ func (r *Runner) runEventLoop() {
 				cache = newCard()
 				r.ch = make(chan any, 1024)
 				r.Error = x.err
+				r.tests = nil
+				break innerLoop
+
+			case evSetParam:
+				cache = newCard()
+				r.ch = make(chan any, 1024)
+				r.params.Set(x.name, x.value)
+				break innerLoop
+
+			case evRerun:
+				cache = newCard()
+				r.ch = make(chan any, 1024)
 				break innerLoop
 
+			case evInterrupt:
+				interrupt(Error{Title: "Stopped", Err: errors.New("the devcard has been stopped")})
+
 			case evClose:
 				cancel()
 				close(r.Updates)
@@ -120,11 +284,25 @@ func (r *Runner) runEventLoop() {
 
 			case evBuilt:
 				built = time.Now().UnixMilli()
-				r.Updates <- Meta{BuildTime: formatTime(built - started)}
+				result.BuildTime = formatTime(built - started)
+				r.Updates <- Meta{BuildTime: result.BuildTime}
 
 			case evFinish:
 				finished = time.Now().UnixMilli()
-				r.Updates <- Meta{RunTime: formatTime(finished - built)}
+				running = false
+				result.RunTime = formatTime(finished - built)
+				r.Updates <- Meta{RunTime: result.RunTime, Finished: true}
+
+			case evDone:
+				if r.env.History != nil {
+					run := r.env.History.add(r.DevcardName, r.params.Encode(), *result)
+					if len(run.Changed) > 0 {
+						r.Updates <- Changes{Cells: slices.DeleteFunc(slices.Clone(result.Cells), func(c Cell) bool {
+							return !slices.Contains(run.Changed, c.Id)
+						})}
+					}
+				}
+				r.Updates <- *result
 
 			case Heartbeat:
 				now := time.Now().UnixMilli()
@@ -133,6 +311,9 @@ func (r *Runner) runEventLoop() {
 					r.Updates <- Meta{BuildTime: formatTime(now - started)}
 				case finished == 0:
 					r.Updates <- Meta{RunTime: formatTime(now - built)}
+					if timeout := r.env.Timeout; timeout > 0 && now-built > timeout.Milliseconds() {
+						interrupt(Error{Title: "Timeout", Err: fmt.Errorf("the devcard has been running for more than %s", timeout)})
+					}
 				default:
 					r.Updates <- Heartbeat{}
 					break
@@ -148,24 +329,45 @@ func (r *Runner) runEventLoop() {
 				}()
 
 			case Title:
+				result.Title = x.Title
 				r.Updates <- e
 
+			case Tests:
+				if !x.Running && !x.Interrupted {
+					r.tests = &x
+				}
+				r.Updates <- x
+
 			case CSS:
 				x.makeStylesheet(*r.cfg)
+				result.CSS = x
 				r.Updates <- x
 
 			case evCell:
-				html := render.RenderCell(highlighter, x.Cell)
+				html := render.RenderCell(highlighter, r.env.Source, r.fileURL, r.subtreeURL, x.Cell)
+				cell := Cell{Id: x.Id, Content: html, Raw: x.Cell, Line: x.Line}
+				if rel, err := filepath.Rel(r.env.Dir, x.File); err == nil && filepath.IsLocal(rel) {
+					// The devcard is built from the fork, whose layout
+					// mirrors the project dir.
+					cell.Path = rel
+				}
+				result.addCell(cell)
 				if cache != nil {
-					cache.addCell(Cell{x.Id, html})
+					cache.addCell(cell)
 				} else {
-					r.Updates <- Cell{x.Id, html}
+					r.Updates <- cell
 				}
 
 			case Error:
+				if interrupted {
+					// The error is caused by the interruption.
+					break
+				}
+				result.Errors = append(result.Errors, x)
 				r.ch <- evFlush{x}
 
 			case Stdout:
+				result.Stdout += x.Line
 				if cache != nil {
 					cache.Stdout += x.Line
 				} else {
@@ -173,6 +375,7 @@ func (r *Runner) runEventLoop() {
 				}
 
 			case Stderr:
+				result.Stderr += x.Line
 				if cache != nil {
 					cache.Stderr += x.Line
 				} else {
@@ -222,18 +425,33 @@ func (evRestart) updateMessage() {}
 
 type evClose struct{}
 
+type evSetParam struct {
+	name, value string
+}
+
+type (
+	evInterrupt struct{}
+	evRerun     struct{}
+)
+
 type (
 	evBuilt  struct{}
 	evFinish struct{}
+	evDone   struct{}
 	evFlush  struct{ withError Error }
 )
 
 type evCell struct {
 	Id   string
 	Cell devcard.Cell
+
+	// File and Line locate the code that created the cell.
+	File string
+	Line int
 }
 
 func (evBuilt) updateMessage()  {}
 func (evFinish) updateMessage() {}
+func (evDone) updateMessage()   {}
 func (evFlush) updateMessage()  {}
 func (evCell) updateMessage()   {}
@@ -1,24 +1,132 @@
 package runner
 
-func Test() int {
-	// r.runLock.Lock()
-	// defer r.runLock.Unlock()
-	//
-	// cmd := exec.CommandContext(ctx, "go", "test", r.DevcardMeta.ImportPath)
-	// cmd.Dir = r.Dir
-	// b, err := cmd.CombinedOutput()
-	// if err == nil {
-	// 	return 0
-	// }
-	//
-	// failedTests := 0
-	// for _, line := range strings.Split(string(b), "\n") {
-	// 	if strings.HasPrefix(line, "--- FAIL:") {
-	// 		failedTests++
-	// 	}
-	// }
-	//
-	// return failedTests
-
-	return 0
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Tests is the status of the tests of the devcard's package. It's sent when
+// the tests start, and then each time a test finishes.
+type Tests struct {
+	Running  bool
+	Passed   int
+	Failures []TestFailure
+
+	// Interrupted is true if the tests have been stopped along with the
+	// devcard before they finished.
+	Interrupted bool
+}
+
+// TestFailure is a failed test (or a failed build of the tests).
+type TestFailure struct {
+	Name   string
+	Output string
+}
+
+func (Tests) updateMessage() {}
+
+// testEvent is an event printed by "go test -json" (see "go doc test2json").
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// runTests runs the tests of the devcard's package in the fork, and sends
+// their status to updates.
+func (r *Runner) runTests(ctx context.Context, updates chan<- any) {
+	status := Tests{Running: true}
+	send := func() {
+		status.Failures = append([]TestFailure(nil), status.Failures...)
+		switch {
+		case ctx.Err() == nil:
+			updates <- status
+		case !status.Running:
+			// The final status is sent even if the devcard has been
+			// stopped, but it mustn't block in case updates are no longer
+			// read.
+			select {
+			case updates <- status:
+			default:
+			}
+		}
+	}
+	send()
+
+	// The test that runs the devcards located in the test files is skipped.
+	cmd := exec.CommandContext(ctx, "go", "test", "-json", "-skip", "^Test_devcardMain$", ".")
+	cmd.Dir = filepath.Join(r.env.Dir, filepath.Dir(r.cardMeta.Path))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		status.Running = false
+		status.Interrupted = ctx.Err() != nil
+		status.Failures = append(status.Failures, TestFailure{Name: "go test", Output: err.Error()})
+		send()
+		return
+	}
+
+	// The output of the tests, by the test's name. The output of the
+	// subtests goes to their top-level tests.
+	outputs := map[string]*strings.Builder{}
+	var packageOutput strings.Builder
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, maxMessageSize)
+	for scanner.Scan() {
+		var e testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			packageOutput.WriteString(scanner.Text() + "\n")
+			continue
+		}
+		name, _, subtest := strings.Cut(e.Test, "/")
+		switch {
+		case e.Action == "build-output", e.Test == "" && e.Action == "output":
+			packageOutput.WriteString(e.Output)
+		case e.Action == "output":
+			if outputs[name] == nil {
+				outputs[name] = new(strings.Builder)
+			}
+			outputs[name].WriteString(e.Output)
+		case e.Test == "" || subtest:
+			// Only the results of the top-level tests are counted.
+		case e.Action == "pass":
+			status.Passed++
+			send()
+		case e.Action == "fail":
+			output := ""
+			if outputs[name] != nil {
+				output = outputs[name].String()
+			}
+			status.Failures = append(status.Failures, TestFailure{Name: name, Output: output})
+			send()
+		}
+	}
+	// Drain the rest of the output in case the scanner has failed.
+	io.Copy(io.Discard, stdout)
+
+	err = cmd.Wait()
+	status.Running = false
+	switch {
+	case ctx.Err() != nil:
+		// The tests have been killed along with the devcard.
+		status.Interrupted = true
+	case err != nil && len(status.Failures) == 0:
+		// The tests failed to build, or the package failed outside of any test.
+		output := packageOutput.String() + stderr.String()
+		if output == "" {
+			output = err.Error()
+		}
+		status.Failures = append(status.Failures, TestFailure{Name: "go test", Output: output})
+	}
+	send()
 }
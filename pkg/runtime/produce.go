@@ -3,6 +3,7 @@ package runtime
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -53,8 +54,14 @@ func functionName(fn interface{}) string {
 
 // ProduceDevcard creates an empty devcard, fills it with content by running the producer function,
 // marshals it to JSON, and writes to outFile.
-func ProduceDevcardWithTCP(address, tempDir string, producer devcard.DevcardProducer) {
-	produce(address, tempDir, producer)
+//
+// params is a URL-encoded query with the values of the devcard's inputs.
+func ProduceDevcardWithTCP(address, tempDir, params string, producer devcard.DevcardProducer) {
+	values, err := url.ParseQuery(params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't parse devcard parameters: %s\n", err)
+	}
+	produce(address, tempDir, values, producer)
 }
 
 // ProduceDevcard creates an empty devcard, fills it with content by running the producer function,
@@ -62,7 +69,7 @@ func ProduceDevcardWithTCP(address, tempDir string, producer devcard.DevcardProd
 func ProduceDevcardWithJSON(tempDir string, producer devcard.DevcardProducer) {
 	outFile := filepath.Join(tempDir, "devcard.json")
 	writeStubDevcard(outFile, functionName(producer))
-	dc := produce("", tempDir, producer)
+	dc := produce("", tempDir, nil, producer)
 	write(outFile, dc)
 }
 
@@ -71,4 +78,4 @@ func TransientDir(dir string) string {
 }
 
 //go:linkname produce github.com/igorhub/devcard.produce
-func produce(netAddress, tempDir string, producer devcard.DevcardProducer) *devcard.Devcard
+func produce(netAddress, tempDir string, params url.Values, producer devcard.DevcardProducer) *devcard.Devcard
@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	_ "unsafe"
+
+	"github.com/igorhub/devcard"
+)
+
+// Snapshot marshals the devcard's title and cells into the snapshot format
+// (see [devcard.SnapshotDir]).
+func Snapshot(title string, cells []devcard.Cell) ([]byte, error) {
+	return snapshot(title, cells)
+}
+
+// DiffSnapshots compares two snapshots cell by cell. It returns a readable
+// description of their differences, or an empty string if they match.
+func DiffSnapshots(want, got []byte) string {
+	return diffSnapshots(want, got)
+}
+
+// VisibleDiffLines reports which lines of the diff (see [devcard.DiffCell])
+// are shown: the changed lines and the unchanged lines within context lines
+// from them. If nothing has changed, all lines are shown.
+func VisibleDiffLines(lines []devcard.DiffLine, context int) []bool {
+	return visibleDiffLines(lines, context)
+}
+
+// FormatDiff formats the lines of the diff in the manner of "diff -u",
+// leaving out the unchanged lines that are farther than context lines away
+// from the changes.
+func FormatDiff(lines []devcard.DiffLine, context int) string {
+	return formatDiff(lines, context)
+}
+
+//go:linkname visibleDiffLines github.com/igorhub/devcard.visibleDiffLines
+func visibleDiffLines(lines []devcard.DiffLine, context int) []bool
+
+//go:linkname formatDiff github.com/igorhub/devcard.formatDiff
+func formatDiff(lines []devcard.DiffLine, context int) string
+
+//go:linkname snapshot github.com/igorhub/devcard.snapshot
+func snapshot(title string, cells []devcard.Cell) ([]byte, error)
+
+//go:linkname diffSnapshots github.com/igorhub/devcard.diffSnapshots
+func diffSnapshots(want, got []byte) string
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/internal/runner"
+)
+
+// The JSON API lets the editor plugins and scripts use the devcards without
+// scraping the HTML pages:
+//
+//	GET /api/projects                                lists the projects
+//	GET /api/projects/{project}/devcards             lists the project's devcards
+//	GET /api/projects/{project}/devcards/{name}/run  runs the devcard
+//
+// The query string of the run request sets the devcard's parameters (see
+// [devcard.Devcard.Param]).
+func (s *server) addAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/projects", s.handleAPIProjects)
+	mux.HandleFunc("GET /api/projects/{project}/devcards", s.handleAPIDevcards)
+	mux.HandleFunc("GET /api/projects/{project}/devcards/{devcard}/run", s.handleAPIRun)
+}
+
+type apiProject struct {
+	Name  string `json:"name"`
+	Dir   string `json:"dir"`
+	Error string `json:"error,omitempty"`
+}
+
+type apiCell struct {
+	Id   string       `json:"id"`
+	Type string       `json:"type"`
+	HTML string       `json:"html"`
+	Cell devcard.Cell `json:"cell"`
+
+	// The location of the code that created the cell; Path is relative to
+	// the project's dir.
+	Path string `json:"path,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+type apiError struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+type apiRun struct {
+	Title     string     `json:"title"`
+	Cells     []apiCell  `json:"cells"`
+	Stdout    string     `json:"stdout"`
+	Stderr    string     `json:"stderr"`
+	BuildTime string     `json:"build_time"`
+	RunTime   string     `json:"run_time"`
+	Errors    []apiError `json:"errors"`
+}
+
+func (s *server) handleAPIProjects(w http.ResponseWriter, r *http.Request) {
+	projects := []apiProject{}
+	for _, cfgProject := range s.cfg.Projects {
+		p := s.projects[cfgProject.Name]
+		if p == nil {
+			continue
+		}
+		project := apiProject{Name: p.Name, Dir: p.Dir}
+		if err := p.Sync(); err != nil {
+			project.Error = err.Error()
+		}
+		projects = append(projects, project)
+	}
+	writeAPIResponse(w, http.StatusOK, projects)
+}
+
+func (s *server) handleAPIDevcards(w http.ResponseWriter, r *http.Request) {
+	p := s.projects[r.PathValue("project")]
+	if p == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("no such project: %s", r.PathValue("project")))
+		return
+	}
+	if err := p.Sync(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	cards := p.GetDevcards()
+	if cards == nil {
+		cards = []devcard.DevcardMeta{}
+	}
+	writeAPIResponse(w, http.StatusOK, cards)
+}
+
+func (s *server) handleAPIRun(w http.ResponseWriter, r *http.Request) {
+	p := s.projects[r.PathValue("project")]
+	if p == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("no such project: %s", r.PathValue("project")))
+		return
+	}
+	devcardName := r.PathValue("devcard")
+	if err := p.Sync(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if p.GetDevcards().Lookup(devcardName).Name == "" {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("no such devcard in %s: %s", p.Name, devcardName))
+		return
+	}
+
+	var run apiRun
+	err := runDevcard(r.Context(), p, devcardName, r.URL.Query(), func(result runner.Result) error {
+		run = apiRun{
+			Title:     result.Title,
+			Cells:     []apiCell{},
+			Stdout:    result.Stdout,
+			Stderr:    result.Stderr,
+			BuildTime: result.BuildTime,
+			RunTime:   result.RunTime,
+			Errors:    []apiError{},
+		}
+		for _, cell := range result.Cells {
+			// The runner is stopped once the request is served, so the images
+			// are embedded into the HTML.
+			html, err := exportedCell(cell, dataURL)
+			if err != nil {
+				return err
+			}
+			run.Cells = append(run.Cells, apiCell{
+				Id:   cell.Id,
+				Type: cell.Raw.Type(),
+				HTML: html,
+				Cell: cell.Raw,
+				Path: cell.Path,
+				Line: cell.Line,
+			})
+		}
+		for _, e := range result.Errors {
+			apiErr := apiError{Title: e.Title}
+			if e.Err != nil {
+				apiErr.Message = e.Err.Error()
+			}
+			run.Errors = append(run.Errors, apiErr)
+		}
+		return nil
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, run)
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		log.Println("API response error: " + err.Error())
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
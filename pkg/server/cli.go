@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/internal/config"
+	"github.com/igorhub/devcard/pkg/internal/project"
+	"github.com/igorhub/devcard/pkg/internal/render"
+	"github.com/igorhub/devcard/pkg/internal/runner"
+)
+
+// Formats of [RunDevcard] output.
+const (
+	FormatMarkdown = "md"
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+)
+
+// RunDevcard builds and runs the devcard, and writes the result into w in the
+// given format.
+//
+// When projectName is empty, the project containing the current working
+// directory is used. RunDevcard returns an error if the devcard fails to
+// build, panics, or has failed checks.
+func RunDevcard(w io.Writer, projectName, devcardName, format string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	if format != FormatMarkdown && format != FormatJSON && format != FormatHTML {
+		return fmt.Errorf("run: unknown format %q", format)
+	}
+	p, err := openProject(&cfg, projectName)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	defer p.Shutdown()
+
+	meta := p.GetDevcards().Lookup(devcardName)
+	if meta.Name == "" {
+		return fmt.Errorf("run: devcard %s not found in %s", devcardName, p.Name)
+	}
+
+	var result runner.Result
+	err = runDevcard(context.Background(), p, devcardName, nil, func(r runner.Result) error {
+		result = r
+		switch format {
+		case FormatMarkdown:
+			return writeMarkdown(w, result, meta, p.Source)
+		case FormatJSON:
+			return writeJSON(w, result)
+		default:
+			return writeHTML(w, result, meta)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("run %s: %w", devcardName, err)
+	}
+
+	if len(result.Errors) > 0 {
+		e := result.Errors[0]
+		return fmt.Errorf("run %s: %s: %w", devcardName, e.Title, e.Err)
+	}
+	if panicked(result) {
+		return fmt.Errorf("run %s: devcard panicked", devcardName)
+	}
+	if passed, failed := countChecks(result); failed > 0 {
+		return fmt.Errorf("run %s: %d of %d checks failed", devcardName, failed, passed+failed)
+	}
+	return nil
+}
+
+// countChecks returns the numbers of the passed and failed checks.
+func countChecks(result runner.Result) (passed, failed int) {
+	for _, cell := range result.Cells {
+		c, ok := cell.Raw.(*devcard.CheckCell)
+		switch {
+		case !ok:
+		case c.Passed:
+			passed++
+		default:
+			failed++
+		}
+	}
+	return passed, failed
+}
+
+// panicked reports whether the devcard panicked during the run.
+func panicked(result runner.Result) bool {
+	for _, cell := range result.Cells {
+		if c, ok := cell.Raw.(*devcard.ErrorCell); ok && c.Title == "Panic!" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMarkdown writes the devcard as Markdown, with the images embedded as
+// data URLs, since the runner's directory is removed after the run.
+func writeMarkdown(w io.Writer, result runner.Result, meta devcard.DevcardMeta, source render.SourceFunc) error {
+	blocks := []string{"# " + exportedTitle(result, meta)}
+	for _, cell := range result.Cells {
+		md, err := markdownCell(source, cell)
+		if err != nil {
+			return err
+		}
+		if md = strings.TrimRight(md, "\n"); md != "" {
+			blocks = append(blocks, md)
+		}
+	}
+	for _, e := range result.Errors {
+		blocks = append(blocks, "## "+e.Title, render.CodeBlock("", e.Err.Error()))
+	}
+	if result.Stdout != "" {
+		blocks = append(blocks, "## Stdout", render.CodeBlock("", result.Stdout))
+	}
+	if result.Stderr != "" {
+		blocks = append(blocks, "## Stderr", render.CodeBlock("", result.Stderr))
+	}
+	_, err := io.WriteString(w, strings.Join(blocks, "\n\n")+"\n")
+	return err
+}
+
+// markdownCell renders the cell as Markdown, with the images embedded as data
+// URLs.
+func markdownCell(source render.SourceFunc, cell runner.Cell) (string, error) {
+	c, ok := cell.Raw.(*devcard.ImageCell)
+	if !ok || c.Error != nil {
+		return render.RenderMarkdown(source, cell.Raw), nil
+	}
+	urls := map[string]string{}
+	for _, img := range c.Images {
+		url, err := dataURL(img.Path)
+		if err != nil {
+			return "", err
+		}
+		urls[img.Path] = url
+	}
+	return render.MarkdownImage(c, func(path string) string { return urls[path] }), nil
+}
+
+func writeJSON(w io.Writer, result runner.Result) error {
+	dc := &devcard.Devcard{Title: result.Title, Cells: []devcard.Cell{}}
+	for _, cell := range result.Cells {
+		dc.Cells = append(dc.Cells, cell.Raw)
+	}
+	data, err := json.MarshalIndent(dc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// writeHTML writes a self-contained page with the images embedded as data
+// URLs.
+func writeHTML(w io.Writer, result runner.Result, meta devcard.DevcardMeta) error {
+	cells, err := exportedCells(result, dataURL)
+	if err != nil {
+		return err
+	}
+	return exportedDevcardPage(exportedTitle(result, meta), result, cells, navBar{}).Render(context.Background(), w)
+}
+
+// dataURL returns the content of the file as a data URL.
+func dataURL(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + mime.TypeByExtension(filepath.Ext(path)) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func loadConfig() (config.Config, error) {
+	cfg := config.LoadConfig()
+	if cfg.Err != nil && !errors.Is(cfg.Err, fs.ErrNotExist) {
+		return cfg, cfg.Err
+	}
+	return cfg, nil
+}
+
+// openProject opens the project (see [findProject]) and waits until it's
+// ready to run devcards.
+func openProject(cfg *config.Config, name string) (*project.Project, error) {
+	projectCfg, err := findProject(*cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	// The results of the tests aren't reported from the command line.
+	projectCfg.RunTests = false
+	p := project.NewProject(cfg, projectCfg)
+	if err := p.Sync(); err != nil {
+		p.Shutdown()
+		return nil, fmt.Errorf("%s: %w", p.Name, err)
+	}
+	return p, nil
+}
+
+// findProject looks up the project by its name. When the name is empty, it
+// picks the project containing the current working directory, or the only
+// project in the config.
+func findProject(cfg config.Config, name string) (config.ProjectConfig, error) {
+	if name != "" {
+		for _, p := range cfg.Projects {
+			if p.Name == name {
+				return p, nil
+			}
+		}
+		return config.ProjectConfig{}, fmt.Errorf("no such project: %s", name)
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		for _, p := range cfg.Projects {
+			rel, err := filepath.Rel(p.Dir, wd)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return p, nil
+			}
+		}
+	}
+
+	if len(cfg.Projects) == 1 {
+		return cfg.Projects[0], nil
+	}
+	return config.ProjectConfig{}, errors.New("unable to determine the project; specify it with -project")
+}
+
+// runDevcard runs the devcard with the given parameters to completion and
+// passes the result to f. The runner is kept alive until f returns, so that
+// the files produced by the devcard (such as images) are still available.
+//
+// If ctx is cancelled before the devcard finishes, the runner is stopped, and
+// runDevcard returns the context's error.
+func runDevcard(ctx context.Context, p *project.Project, devcardName string, params url.Values, f func(runner.Result) error) error {
+	runnerId := p.StartRunner(devcardName, params)
+	defer p.StopRunner(runnerId)
+	updates := p.GetRunner(runnerId)
+	for {
+		select {
+		case msg, ok := <-updates:
+			if !ok {
+				return f(runner.Result{})
+			}
+			if result, ok := msg.(runner.Result); ok {
+				return f(result)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os/exec"
@@ -31,22 +32,63 @@ func (s *server) handleEdit(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	var err error
-	switch {
-	case s.cfg.Opener != "":
-		err = openCustom(s.cfg.Opener, filepath.Join(project.Dir, meta.Path), meta.Line)
-	case strings.ToLower(s.cfg.Editor) == "emacs":
-		err = openInEmacs(filepath.Join(project.Dir, meta.Path), meta.Line)
-	case strings.ToLower(s.cfg.Editor) == "vscode":
-		err = openInVscode(filepath.Join(project.Dir, meta.Path), meta.Line)
+	// By default, the devcard itself is opened; the query may point to
+	// another location within the project, such as the code that created a
+	// cell.
+	path, line := meta.Path, meta.Line
+	if q := req.URL.Query(); q.Has("path") {
+		path = filepath.FromSlash(q.Get("path"))
+		n, err := strconv.Atoi(q.Get("line"))
+		if !filepath.IsLocal(path) || err != nil {
+			w.Write(errorHeader)
+			w.Write([]byte("Invalid location: " + q.Get("path") + ":" + q.Get("line")))
+			return
+		}
+		line = n
 	}
+	path = filepath.Join(project.Dir, path)
 
+	err := s.openInEditor(path, line)
 	if err != nil {
 		w.Write(errorHeader)
 		w.Write([]byte(err.Error()))
 	}
 }
 
+// openInEditor opens the file at the line in the configured editor.
+func (s *server) openInEditor(path string, line int) error {
+	if s.cfg.Opener != "" {
+		return openCustom(s.cfg.Opener, path, line)
+	}
+
+	switch editor := strings.ToLower(s.cfg.Editor); editor {
+	case "emacs":
+		return openInEmacs(path, line)
+	case "vscode":
+		return openInVscode(path, line)
+	case "neovim", "nvim":
+		return openInNeovim(s.cfg.NeovimServer, path, line)
+	case "idea", "goland":
+		return exec.Command(editor, "--line", strconv.Itoa(line), path).Run()
+	case "zed":
+		return exec.Command("zed", location(path, line)).Run()
+	case "sublime", "subl":
+		return exec.Command("subl", location(path, line)).Run()
+	case "helix", "hx":
+		return openInHelix(s.cfg.HelixPane, path, line)
+	case "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported editor: %s", s.cfg.Editor)
+	}
+}
+
+// location returns the path:line:column notation understood by most editors.
+// The column is always 1, as the column of the code isn't known.
+func location(path string, line int) string {
+	return fmt.Sprintf("%s:%d:1", path, line)
+}
+
 func openInEmacs(path string, line int) error {
 	cmd := fmt.Sprintf(`(progn
 (find-file "%s")
@@ -59,6 +101,34 @@ func openInVscode(path string, line int) error {
 	return exec.Command("code", "-g", path+":"+strconv.Itoa(line)).Run()
 }
 
+func openInNeovim(server, path string, line int) error {
+	if server == "" {
+		return errors.New("neovim-server is not set in the config")
+	}
+	path = strings.ReplaceAll(path, "'", "''")
+	expr := fmt.Sprintf(`execute('edit +%d ' .. fnameescape('%s'))`, line, path)
+	return exec.Command("nvim", "--server", server, "--remote-expr", expr).Run()
+}
+
+func openInHelix(pane, path string, line int) error {
+	if pane == "" {
+		return errors.New("helix-tmux-pane is not set in the config")
+	}
+	return exec.Command("tmux", "send-keys", "-t", pane, "Escape", ":open "+location(path, line), "Enter").Run()
+}
+
+// openCustom runs the custom opener. If the opener contains placeholders, it's
+// treated as a command line; otherwise, it's called with the path and the
+// line as arguments.
 func openCustom(opener, path string, line int) error {
-	return exec.Command(opener, path, strconv.Itoa(line)).Run()
+	if !strings.ContainsAny(opener, "{}") {
+		return exec.Command(opener, path, strconv.Itoa(line)).Run()
+	}
+
+	r := strings.NewReplacer("{file}", path, "{line}", strconv.Itoa(line), "{column}", "1")
+	args := strings.Fields(opener)
+	for i := range args {
+		args[i] = r.Replace(args[i])
+	}
+	return exec.Command(args[0], args[1:]...).Run()
 }
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/internal/project"
+	"github.com/igorhub/devcard/pkg/internal/render"
+	"github.com/igorhub/devcard/pkg/internal/runner"
+)
+
+// Export builds and runs the devcards of the project, and writes them into
+// outDir as self-contained HTML pages, along with index.html.
+//
+// When projectName is empty, the project containing the current working
+// directory is exported. When patterns are given, only the devcards with
+// names matching any of them (as in [path.Match]) are exported.
+func Export(projectName, outDir string, patterns []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	p, err := openProject(&cfg, projectName)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	defer p.Shutdown()
+
+	cardsMeta, err := filterDevcards(p.GetDevcards(), patterns)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", p.Name, err)
+	}
+	if len(cardsMeta) == 0 {
+		return fmt.Errorf("export %s: no devcards found", p.Name)
+	}
+
+	if err := os.MkdirAll(filepath.Join(outDir, "images"), 0755); err != nil {
+		return fmt.Errorf("export %s: %w", p.Name, err)
+	}
+
+	var failed []string
+	for _, meta := range cardsMeta {
+		fmt.Fprintf(os.Stderr, "Exporting %s\n", meta.Name)
+		ok, err := exportDevcard(p, cardsMeta, meta, outDir)
+		if err != nil {
+			return fmt.Errorf("export %s: %w", meta.Name, err)
+		}
+		if !ok {
+			failed = append(failed, meta.Name)
+		}
+	}
+
+	err = writePage(filepath.Join(outDir, "index.html"), exportedIndexPage(p.Name, cfg.CSS(), cardsMeta))
+	if err != nil {
+		return fmt.Errorf("export %s: %w", p.Name, err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("export %s: devcards failed to run: %s", p.Name, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func filterDevcards(cardsMeta project.DevcardsMetaSlice, patterns []string) (project.DevcardsMetaSlice, error) {
+	if len(patterns) == 0 {
+		return cardsMeta, nil
+	}
+	var result project.DevcardsMetaSlice
+	for _, meta := range cardsMeta {
+		for _, pattern := range patterns {
+			ok, err := path.Match(pattern, meta.Name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, meta)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// exportDevcard writes the devcard's page into outDir. It reports whether the
+// devcard ran without errors.
+func exportDevcard(p *project.Project, cardsMeta project.DevcardsMetaSlice, meta devcard.DevcardMeta, outDir string) (ok bool, err error) {
+	err = runDevcard(context.Background(), p, meta.Name, nil, func(result runner.Result) error {
+		ok = len(result.Errors) == 0
+		return writeDevcardPage(result, cardsMeta, meta, outDir)
+	})
+	return ok, err
+}
+
+func writeDevcardPage(result runner.Result, cardsMeta project.DevcardsMetaSlice, meta devcard.DevcardMeta, outDir string) error {
+	n := 0
+	cells, err := exportedCells(result, func(path string) (string, error) {
+		n++
+		name := meta.Name + "-" + strconv.Itoa(n) + filepath.Ext(path)
+		return "images/" + name, copyFile(path, filepath.Join(outDir, "images", name))
+	})
+	if err != nil {
+		return err
+	}
+	page := exportedDevcardPage(exportedTitle(result, meta), result, cells, makeNavBar(cardsMeta, meta.Name))
+	return writePage(filepath.Join(outDir, meta.Name+".html"), page)
+}
+
+// exportedCells renders the cells of the result, with the images located at
+// URLs returned by imageURL.
+func exportedCells(result runner.Result, imageURL func(path string) (string, error)) ([]string, error) {
+	var cells []string
+	for _, cell := range result.Cells {
+		content, err := exportedCell(cell, imageURL)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, fmt.Sprintf(`<div class="-dc-cell" id="%s">%s</div>`, cell.Id, content))
+	}
+	return cells, nil
+}
+
+// exportHighlighter highlights the code of the exported cells. The
+// highlighting is done with CSS classes, so the highlighter's style doesn't
+// matter.
+var exportHighlighter = render.NewHighlighter("")
+
+// exportedCell renders the cell, with the images located at URLs returned by
+// imageURL.
+func exportedCell(cell runner.Cell, imageURL func(path string) (string, error)) (string, error) {
+	if c, ok := cell.Raw.(*devcard.ValueCell); ok {
+		// The exported cells can't load the subtrees of the values from the
+		// server, so the trees are rendered in full.
+		return render.RenderCell(exportHighlighter, nil, nil, nil, c), nil
+	}
+	c, ok := cell.Raw.(*devcard.ImageCell)
+	if !ok || c.Error != nil {
+		return cell.Content, nil
+	}
+	urls := map[string]string{}
+	for _, img := range c.Images {
+		url, err := imageURL(img.Path)
+		if err != nil {
+			return "", err
+		}
+		urls[img.Path] = url
+	}
+	return render.RenderImage(c, func(path string) string { return urls[path] }), nil
+}
+
+func exportedTitle(result runner.Result, meta devcard.DevcardMeta) string {
+	if result.Title != "" {
+		return result.Title
+	}
+	return meta.Caption()
+}
+
+func exportedURL(devcardName string) templ.SafeURL {
+	return templ.SafeURL(devcardName + ".html")
+}
+
+func writePage(path string, page templ.Component) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = page.Render(context.Background(), f)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err2 := out.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
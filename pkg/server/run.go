@@ -2,11 +2,12 @@ package server
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"time"
 
 	"github.com/igorhub/devcard/pkg/internal/config"
@@ -23,7 +24,7 @@ func Run(port int) error {
 func run(cfg config.Config) error {
 	server := NewServer(cfg)
 	httpServer := http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Port),
+		Addr:    net.JoinHostPort(cfg.Bind, strconv.Itoa(cfg.Port)),
 		Handler: server,
 	}
 
@@ -34,7 +35,11 @@ func run(cfg config.Config) error {
 	var serverError error
 	go func() {
 		log.Printf("Starting devcards...")
-		log.Printf("Access the app via the following URL: http://127.0.0.1:%d\n", cfg.Port)
+		host := cfg.Bind
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		log.Printf("Access the app via the following URL: http://%s\n", net.JoinHostPort(host, strconv.Itoa(cfg.Port)))
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Println("Error running httpServer.ListenAndServe:", err)
 			serverError = err
@@ -5,12 +5,17 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/igorhub/devcard"
 	"github.com/igorhub/devcard/pkg/internal/config"
 	"github.com/igorhub/devcard/pkg/internal/project"
 	"github.com/igorhub/devcard/pkg/internal/runner"
@@ -95,16 +100,19 @@ func (s *server) addRoutes(mux *http.ServeMux, cfg config.Config) {
 	mux.HandleFunc("GET /devcards/{project}", s.handleProject)
 	mux.HandleFunc("GET /devcards/{project}/{devcard}", s.handleDevcard)
 	mux.HandleFunc("GET /devcards/{project}/{devcard}/edit", s.handleEdit)
+	mux.HandleFunc("GET /devcards/{project}/{devcard}/history", s.handleHistory)
+	s.addAPIRoutes(mux)
 	mux.HandleFunc("POST /devcards/sse", s.handleSSE)
+	mux.HandleFunc("POST /devcards/input", s.handleInput)
+	mux.HandleFunc("POST /devcards/stop", s.handleStop)
+	mux.HandleFunc("POST /devcards/rerun", s.handleRerun)
 
 	mux.HandleFunc("GET /devcards/css", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/css")
 		w.Write([]byte(s.cfg.CSS()))
 	})
-	mux.HandleFunc("GET /file", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Query().Get("path")
-		http.ServeFile(w, r, path)
-	})
+	mux.HandleFunc("GET /file", s.handleFile)
+	mux.HandleFunc("GET /tree", s.handleTree)
 	mux.HandleFunc("GET /devcards/favicon.png", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFileFS(w, r, assetsFS, "/assets/favicon.png")
 	})
@@ -153,7 +161,28 @@ func mergeRefresh(sse *datastar.ServerSentEventGenerator) {
 }
 
 func (s *server) handleHomePage(w http.ResponseWriter, r *http.Request) {
-	homePage(s.cfg).Render(r.Context(), w)
+	var cards []searchEntry
+	for _, cfgProject := range s.cfg.Projects {
+		if p := s.projects[cfgProject.Name]; p != nil {
+			for _, meta := range p.GetDevcards() {
+				cards = append(cards, searchEntry{project: p.Name, meta: meta})
+			}
+		}
+	}
+	homePage(s.cfg, cards).Render(r.Context(), w)
+}
+
+// searchEntry is a devcard that can be found with the search on the home page.
+type searchEntry struct {
+	project string
+	meta    devcard.DevcardMeta
+}
+
+// text returns the lowercase text that the search query is matched against.
+func (e searchEntry) text() string {
+	return strings.ToLower(strings.Join([]string{
+		e.project, e.meta.Name, e.meta.Title, e.meta.Package, e.meta.ImportPath, e.meta.Doc,
+	}, " "))
 }
 
 func (s *server) handleProject(w http.ResponseWriter, r *http.Request) {
@@ -204,12 +233,70 @@ func (s *server) handleDevcard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	navBar := makeNavBar(project.GetDevcards(), devcardName)
-	err := devcardPage(s.cfg, s.cfg.CSS(), projectName, devcardName, devcardName, navBar).Render(r.Context(), w)
+	err := devcardPage(s.cfg, s.cfg.CSS(), projectName, devcardName, devcardName, r.URL.Query(), navBar).Render(r.Context(), w)
 	if err != nil {
 		log.Println("handleDevcard error: " + err.Error())
 	}
 }
 
+// devcardSignals returns the initial datastar signals of the devcard page.
+func devcardSignals(project, name string, params url.Values) string {
+	signals := map[string]any{
+		"devcards": map[string]string{
+			"project":  project,
+			"name":     name,
+			"runnerId": "",
+			"params":   params.Encode(),
+		},
+	}
+	data, err := json.Marshal(signals)
+	if err != nil {
+		panic(fmt.Errorf("devcardSignals: %w", err))
+	}
+	return string(data)
+}
+
+func (s *server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	projectName := r.PathValue("project")
+	devcardName := r.PathValue("devcard")
+
+	project := s.projects[projectName]
+	if project == nil {
+		log.Println("no such project: " + projectName)
+		return
+	}
+
+	err := historyPage(projectName, devcardName, project.History(devcardName)).Render(r.Context(), w)
+	if err != nil {
+		log.Println("handleHistory error: " + err.Error())
+	}
+}
+
+// cellFragment renders the cell within its container. The container holds
+// the location of the code that created the cell, so that the user can open
+// it in the editor.
+func cellFragment(cell runner.Cell, class string) string {
+	var location string
+	if cell.Path != "" {
+		location = fmt.Sprintf(` data-path="%s" data-line="%d"`, html.EscapeString(cell.Path), cell.Line)
+	}
+	return fmt.Sprintf(`<div class="%s" id="%s"%s>%s</div>`, class, cell.Id, location, cell.Content)
+}
+
+// mergeChecks updates the numbers of the passed and failed checks shown in the
+// devcard's status.
+func mergeChecks(sse *datastar.ServerSentEventGenerator, checks map[string]bool) error {
+	var passed, failed int
+	for _, ok := range checks {
+		if ok {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	return mergeSignalsf(sse, `{devcards: {checksPassed: %d, checksFailed: %d}}`, passed, failed)
+}
+
 func (s *server) findRunner(projectName string, runnerId string) chan any {
 	project := s.projects[projectName]
 	if project == nil {
@@ -224,14 +311,93 @@ func (s *server) stopRunner(projectName string, runnerId string) {
 	}
 }
 
+// handleFile serves either a file produced by a devcard, or a file from one of
+// the allowed dirs.
+func (s *server) handleFile(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if token := query.Get("runner"); token != "" {
+		for _, project := range s.projects {
+			if path, ok := project.RunnerFile(token, query.Get("name")); ok {
+				http.ServeFile(w, r, path)
+				return
+			}
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	path := query.Get("path")
+	if !filepath.IsAbs(path) || !s.cfg.AllowsFile(path) {
+		http.Error(w, "403 forbidden", http.StatusForbidden)
+		return
+	}
+	http.ServeFile(w, r, path)
+}
+
+// handleTree serves the rendered children of a node of a value's tree, which
+// are loaded when the node is expanded.
+func (s *server) handleTree(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for _, project := range s.projects {
+		if html, ok := project.RunnerSubtree(query.Get("runner"), query.Get("id")); ok {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, html)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// runnerProject reads the signals from the request's body, and returns the
+// project and the id of the runner.
+func (s *server) runnerProject(r *http.Request) (*project.Project, string) {
+	var x struct {
+		Devcards struct{ Project, RunnerId string }
+	}
+	json.NewDecoder(r.Body).Decode(&x)
+
+	project := s.projects[x.Devcards.Project]
+	if project == nil {
+		log.Println("no such project: " + x.Devcards.Project)
+	}
+	return project, x.Devcards.RunnerId
+}
+
+func (s *server) handleInput(w http.ResponseWriter, r *http.Request) {
+	project, runnerId := s.runnerProject(r)
+	datastar.NewSSE(w, r)
+	if project == nil {
+		return
+	}
+	query := r.URL.Query()
+	project.SetRunnerParam(runnerId, query.Get("name"), query.Get("value"))
+}
+
+func (s *server) handleStop(w http.ResponseWriter, r *http.Request) {
+	project, runnerId := s.runnerProject(r)
+	datastar.NewSSE(w, r)
+	if project != nil {
+		project.InterruptRunner(runnerId)
+	}
+}
+
+func (s *server) handleRerun(w http.ResponseWriter, r *http.Request) {
+	project, runnerId := s.runnerProject(r)
+	datastar.NewSSE(w, r)
+	if project != nil {
+		project.RerunRunner(runnerId)
+	}
+}
+
 func (s *server) handleSSE(w http.ResponseWriter, r *http.Request) {
 	var x struct {
-		Devcards struct{ Project, Name, RunnerId string }
+		Devcards struct{ Project, Name, RunnerId, Params string }
 	}
 	json.NewDecoder(r.Body).Decode(&x)
 
 	sse := datastar.NewSSE(w, r)
 	cells := map[string]bool{}
+	checks := map[string]bool{} // by the cell's id: whether the check has passed
 
 	runnerId := x.Devcards.RunnerId
 	ch := s.findRunner(x.Devcards.Project, runnerId)
@@ -241,7 +407,11 @@ func (s *server) handleSSE(w http.ResponseWriter, r *http.Request) {
 			log.Println("no such project: " + x.Devcards.Project)
 			return
 		}
-		runnerId = project.StartRunner(x.Devcards.Name)
+		params, err := url.ParseQuery(x.Devcards.Params)
+		if err != nil {
+			log.Printf("invalid devcard params %q: %s", x.Devcards.Params, err)
+		}
+		runnerId = project.StartRunner(x.Devcards.Name, params)
 		ch = s.findRunner(x.Devcards.Project, runnerId)
 		mergeSignalsf(sse, `{devcards: {runnerId:'%s'}}`, runnerId)
 	}
@@ -253,17 +423,17 @@ func (s *server) handleSSE(w http.ResponseWriter, r *http.Request) {
 		switch x := msg.(type) {
 		case runner.Meta:
 			if x.BuildTime != "" {
-				mergeSignalsf(sse, `{devcards: {buildTime:'%s'}}`, x.BuildTime)
+				mergeSignalsf(sse, `{devcards: {buildTime:'%s', running:true}}`, x.BuildTime)
 			}
 			if x.RunTime != "" {
-				mergeSignalsf(sse, `{devcards: {runTime:'%s'}}`, x.RunTime)
+				mergeSignalsf(sse, `{devcards: {runTime:'%s', running:%t}}`, x.RunTime, !x.Finished)
 			}
 
 		case runner.Title:
 			err = sse.MergeFragmentf(`<title id="-dc-tab-title">%s</title>`, x.Title)
 			if err == nil {
 				var buf bytes.Buffer
-				dcTitle(x.Title, s.cfg.Editor != "").Render(r.Context(), &buf)
+				dcTitle(x.Title, s.cfg.Editor != "" || s.cfg.Opener != "").Render(r.Context(), &buf)
 				err = sse.MergeFragments(buf.String())
 			}
 
@@ -308,18 +478,34 @@ func (s *server) handleSSE(w http.ResponseWriter, r *http.Request) {
 					datastar.WithSelector("#-dc-cells"))
 			}
 			if err == nil {
-				err = sse.MergeFragmentf(`<div class="-dc-cell" id="%s">%s</div>`, x.Id, x.Content)
+				err = sse.MergeFragments(cellFragment(x, "-dc-cell"))
+			}
+			if c, ok := x.Raw.(*devcard.CheckCell); ok {
+				checks[x.Id] = c.Passed
+				if err == nil {
+					err = mergeChecks(sse, checks)
+				}
+			} else if _, ok := checks[x.Id]; ok {
+				delete(checks, x.Id)
+				if err == nil {
+					err = mergeChecks(sse, checks)
+				}
 			}
 
 		case runner.Card:
 			initStdout, initStderr = false, false
 			cells = map[string]bool{}
 
+			checks = map[string]bool{}
 			var cellsStrs []string
 			for _, cell := range x.Cells {
-				cellsStrs = append(cellsStrs, fmt.Sprintf(`<div class="-dc-cell" id="%s">%s</div>`, cell.Id, cell.Content))
+				cellsStrs = append(cellsStrs, cellFragment(cell, "-dc-cell"))
 				cells[cell.Id] = true
+				if c, ok := cell.Raw.(*devcard.CheckCell); ok {
+					checks[cell.Id] = c.Passed
+				}
 			}
+			mergeChecks(sse, checks)
 
 			var stdout, stderr string
 			if x.Stdout != "" {
@@ -348,9 +534,29 @@ func (s *server) handleSSE(w http.ResponseWriter, r *http.Request) {
 			dcError(runner.Error{}).Render(r.Context(), &buf)
 			err = sse.MergeFragments(buf.String())
 
+		case runner.Changes:
+			for _, cell := range x.Cells {
+				err = sse.MergeFragments(cellFragment(cell, "-dc-cell -dc-changed"))
+				if err != nil {
+					break
+				}
+			}
+
+		case runner.Tests:
+			err = mergeSignalsf(sse, `{devcards: {testsRunning: %t, testsInterrupted: %t, testsPassed: %d, testFailures: %d}}`,
+				x.Running, x.Interrupted, x.Passed, len(x.Failures))
+			if err == nil {
+				var buf bytes.Buffer
+				dcTestFailures(x.Failures).Render(r.Context(), &buf)
+				err = sse.MergeFragments(buf.String())
+			}
+
 		case runner.Heartbeat:
 			err = sse.MergeFragments("")
 
+		case runner.Result:
+			// The page is already up to date.
+
 		default:
 			t := fmt.Sprintf("%T", msg)
 			fmt.Println("[server]", "unknown message", t, ">", msg)
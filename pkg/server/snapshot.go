@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/igorhub/devcard"
+	"github.com/igorhub/devcard/pkg/internal/project"
+	"github.com/igorhub/devcard/pkg/internal/runner"
+	"github.com/igorhub/devcard/pkg/runtime"
+)
+
+// TestSnapshots builds and runs the devcards of the project, and compares them
+// against their snapshots (see [devcard.SnapshotDir]). The report is written
+// into w. When update is true, the snapshots are overwritten instead.
+//
+// When projectName is empty, the project containing the current working
+// directory is used. When patterns are given, only the devcards with names
+// matching any of them (as in [path.Match]) are tested.
+func TestSnapshots(w io.Writer, projectName string, patterns []string, update bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("test: %w", err)
+	}
+	p, err := openProject(&cfg, projectName)
+	if err != nil {
+		return fmt.Errorf("test: %w", err)
+	}
+	defer p.Shutdown()
+
+	cardsMeta, err := filterDevcards(p.GetDevcards(), patterns)
+	if err != nil {
+		return fmt.Errorf("test %s: %w", p.Name, err)
+	}
+	if len(cardsMeta) == 0 {
+		return fmt.Errorf("test %s: no devcards found", p.Name)
+	}
+
+	var failed []string
+	for _, meta := range cardsMeta {
+		var status, report string
+		err := runDevcard(context.Background(), p, meta.Name, nil, func(result runner.Result) (err error) {
+			status, report, err = testSnapshot(p, meta, result, update)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("test %s: %w", meta.Name, err)
+		}
+		fmt.Fprintf(w, "%-4s %s\n", status, meta.Name)
+		if report != "" {
+			fmt.Fprintln(w, report)
+		}
+		if status == "FAIL" {
+			failed = append(failed, meta.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("test %s: devcards failed: %s", p.Name, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func testSnapshot(p *project.Project, meta devcard.DevcardMeta, result runner.Result, update bool) (status, report string, err error) {
+	if len(result.Errors) > 0 {
+		e := result.Errors[0]
+		return "FAIL", e.Title + ": " + e.Err.Error(), nil
+	}
+	if panicked(result) {
+		return "FAIL", "devcard panicked", nil
+	}
+
+	title := result.Title
+	if title == "" {
+		title = "Untitled devcard"
+	}
+	cells := make([]devcard.Cell, len(result.Cells))
+	for i, cell := range result.Cells {
+		cells[i] = cell.Raw
+	}
+	got, err := runtime.Snapshot(title, cells)
+	if err != nil {
+		return "", "", err
+	}
+
+	path := filepath.Join(p.Dir, filepath.Dir(meta.Path), devcard.SnapshotDir, meta.Name+".json")
+	want, err := os.ReadFile(path)
+	switch {
+	case update:
+		if err == nil && runtime.DiffSnapshots(want, got) == "" {
+			return "ok", "", nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", "", err
+		}
+		return "new", "", os.WriteFile(path, got, 0644)
+	case errors.Is(err, fs.ErrNotExist):
+		return "FAIL", "snapshot doesn't exist; run with -update to create it", nil
+	case err != nil:
+		return "", "", err
+	}
+
+	if diff := runtime.DiffSnapshots(want, got); diff != "" {
+		return "FAIL", diff, nil
+	}
+	return "ok", "", nil
+}
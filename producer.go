@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"runtime/debug"
 	"strings"
@@ -13,8 +14,8 @@ import (
 // DevcardProducer is a function that fills an empty devcard with content.
 type DevcardProducer func(*Devcard)
 
-func produce(tcpAddress, tempDir string, producer DevcardProducer) (dc *Devcard) {
-	dc = newDevcard("Untitled devcard", tempDir)
+func produce(tcpAddress, tempDir string, params url.Values, producer DevcardProducer) (dc *Devcard) {
+	dc = newDevcard("Untitled devcard", tempDir, params)
 	current = dc
 
 	done := make(chan struct{})
@@ -38,7 +39,7 @@ func produce(tcpAddress, tempDir string, producer DevcardProducer) (dc *Devcard)
 			switch x := e.(type) {
 			case error:
 				dc.Append("// " + x.Error())
-				dc.Append(pprint(x) + "\n")
+				dc.Append(pprint(x, DefaultLimits) + "\n")
 			case string:
 				dc.Append(x)
 			}
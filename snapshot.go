@@ -0,0 +1,219 @@
+package devcard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// SnapshotUpdateEnv is the environment variable that switches [Snapshot] into
+// the update mode.
+const SnapshotUpdateEnv = "DEVCARD_UPDATE_SNAPSHOTS"
+
+// SnapshotDir is the directory (relative to the package's directory) where the
+// snapshots of the package's devcards are stored.
+const SnapshotDir = "testdata/devcards"
+
+// Snapshot runs the producer and compares the resulting devcard against its
+// snapshot stored in testdata/devcards/<DevcardName>.json. If the devcard
+// doesn't match the snapshot, the test fails with a per-cell diff. If the
+// producer panics, the test fails with the panic's stack trace.
+//
+// When the environment variable DEVCARD_UPDATE_SNAPSHOTS is set to a
+// non-empty value, the snapshot is overwritten with the devcard instead.
+//
+// The producer must be a top-level function, as its name is the name of the
+// snapshot.
+//
+// Example:
+//
+//	func TestDevcardFoobar(t *testing.T) {
+//		devcard.Snapshot(t, DevcardFoobar)
+//	}
+func Snapshot(t testing.TB, producer DevcardProducer) {
+	t.Helper()
+
+	name := runtime.FuncForPC(reflect.ValueOf(producer).Pointer()).Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	if closureName.MatchString(name) {
+		// All closures would share the same snapshot file.
+		t.Fatalf("devcard %s: the producer must be a top-level function, not a closure", name)
+		return
+	}
+
+	got, err := produceSnapshot(t.TempDir(), producer)
+	if err != nil {
+		t.Fatalf("devcard %s: %s", name, err)
+		return
+	}
+
+	path := filepath.Join(SnapshotDir, name+".json")
+	if os.Getenv(SnapshotUpdateEnv) != "" {
+		if err := writeSnapshot(path, got); err != nil {
+			t.Fatalf("devcard %s: %s", name, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("devcard %s: snapshot %s doesn't exist; run the test with %s=1 to create it", name, path, SnapshotUpdateEnv)
+		return
+	}
+	if err != nil {
+		t.Fatalf("devcard %s: %s", name, err)
+		return
+	}
+	if diff := diffSnapshots(want, got); diff != "" {
+		t.Errorf("devcard %s doesn't match its snapshot %s:\n%s", name, path, diff)
+	}
+}
+
+// closureName matches the last element of the names of closures, such as
+// "pkg.TestFoo.func1".
+var closureName = regexp.MustCompile(`^func\d+$`)
+
+func writeSnapshot(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// produceSnapshot runs the producer and marshals the resulting devcard into
+// the snapshot format. The devcard doesn't send its updates anywhere. If the
+// producer panics, the panic is returned as an error.
+func produceSnapshot(tempDir string, producer DevcardProducer) (data []byte, err error) {
+	dc := &Devcard{
+		Title:   "Untitled devcard",
+		TempDir: tempDir,
+		Cells:   []Cell{},
+	}
+	current = dc
+
+	defer func() {
+		if e := recover(); e != nil {
+			data, err = nil, fmt.Errorf("panic: %v\n%s", e, debug.Stack())
+		}
+	}()
+	producer(dc)
+
+	cells := make([]Cell, len(dc.Cells))
+	for i, cell := range dc.Cells {
+		if c, ok := cell.(customCell); ok {
+			cell = c.Cast()
+		}
+		cells[i] = cell
+	}
+	return snapshot(dc.Title, cells)
+}
+
+type snapshotCell struct {
+	Type string          `json:"type"`
+	Cell json.RawMessage `json:"cell"`
+}
+
+type snapshotDevcard struct {
+	Title string         `json:"title"`
+	Cells []snapshotCell `json:"cells"`
+}
+
+// snapshot marshals the devcard's title and cells into the snapshot format.
+//
+// As the images are stored in temporary files, their paths are replaced by
+// the hashes of their content. The values' trees are omitted, as they
+// duplicate the pretty-printed values.
+func snapshot(title string, cells []Cell) ([]byte, error) {
+	s := snapshotDevcard{Title: title, Cells: []snapshotCell{}}
+	for _, cell := range cells {
+		switch c := cell.(type) {
+		case *ImageCell:
+			cell = hashImages(c)
+		case *ValueCell:
+			// The trees duplicate the values.
+			cell = &ValueCell{Values: c.Values}
+		}
+		data, err := json.Marshal(cell)
+		if err != nil {
+			return nil, err
+		}
+		s.Cells = append(s.Cells, snapshotCell{cell.Type(), data})
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func hashImages(c *ImageCell) *ImageCell {
+	result := &ImageCell{Images: make([]AnnotatedImage, len(c.Images)), Error: c.Error}
+	for i, img := range c.Images {
+		result.Images[i].Annotation = img.Annotation
+		data, err := os.ReadFile(img.Path)
+		if err != nil {
+			result.Images[i].Path = "unreadable image: " + filepath.Base(img.Path)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		result.Images[i].Path = "sha256:" + hex.EncodeToString(sum[:]) + filepath.Ext(img.Path)
+	}
+	return result
+}
+
+// diffSnapshots compares two snapshots cell by cell. It returns a readable
+// description of their differences, or an empty string if they match.
+func diffSnapshots(want, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+
+	var w, g snapshotDevcard
+	if err := json.Unmarshal(want, &w); err != nil {
+		return "malformed snapshot: " + err.Error()
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return "malformed devcard: " + err.Error()
+	}
+
+	s := new(strings.Builder)
+	if w.Title != g.Title {
+		fmt.Fprintf(s, "title:\n- %s\n+ %s\n", w.Title, g.Title)
+	}
+	for i := range max(len(w.Cells), len(g.Cells)) {
+		switch {
+		case i >= len(g.Cells):
+			fmt.Fprintf(s, "cell %d (%s): missing\n", i, w.Cells[i].Type)
+		case i >= len(w.Cells):
+			fmt.Fprintf(s, "cell %d (%s): unexpected\n%s\n", i, g.Cells[i].Type, indentJSON(g.Cells[i].Cell))
+		case w.Cells[i].Type != g.Cells[i].Type:
+			fmt.Fprintf(s, "cell %d: %s replaced by %s\n%s\n", i, w.Cells[i].Type, g.Cells[i].Type, indentJSON(g.Cells[i].Cell))
+		default:
+			diff := unifiedDiff(indentJSON(w.Cells[i].Cell), indentJSON(g.Cells[i].Cell), 3)
+			if diff != "" {
+				fmt.Fprintf(s, "cell %d (%s):\n%s", i, g.Cells[i].Type, diff)
+			}
+		}
+	}
+	return s.String()
+}
+
+func indentJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
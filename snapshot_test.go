@@ -0,0 +1,74 @@
+package devcard
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// snapshotT records the failures of Snapshot.
+type snapshotT struct {
+	*testing.T
+	failures []string
+}
+
+func (t *snapshotT) Errorf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *snapshotT) Fatalf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+var snapshotText = "foo"
+
+func DevcardSnapshotted(dc *Devcard) {
+	dc.SetTitle("Snapshotted")
+	dc.Md(snapshotText)
+}
+
+func TestSnapshot(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	snapshot := func(update bool) []string {
+		if update {
+			t.Setenv(SnapshotUpdateEnv, "1")
+		} else {
+			t.Setenv(SnapshotUpdateEnv, "")
+		}
+		st := &snapshotT{T: t}
+		Snapshot(st, DevcardSnapshotted)
+		return st.failures
+	}
+
+	if failures := snapshot(false); len(failures) != 1 {
+		t.Errorf("expected the missing snapshot to be reported, got %q", failures)
+	}
+	if failures := snapshot(true); len(failures) != 0 {
+		t.Errorf("update failed: %q", failures)
+	}
+	if failures := snapshot(false); len(failures) != 0 {
+		t.Errorf("the devcard doesn't match its fresh snapshot: %q", failures)
+	}
+	snapshotText = "bar"
+	defer func() { snapshotText = "foo" }()
+	if failures := snapshot(false); len(failures) != 1 {
+		t.Errorf("expected the changed devcard to fail, got %q", failures)
+	}
+}
+
+func TestSnapshotClosure(t *testing.T) {
+	st := &snapshotT{T: t}
+	Snapshot(st, func(dc *Devcard) {})
+	if len(st.failures) != 1 || !strings.Contains(st.failures[0], "closure") {
+		t.Errorf("expected the closure to be rejected, got %q", st.failures)
+	}
+}
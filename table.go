@@ -0,0 +1,244 @@
+package devcard
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// TableCell is a cell with tabular data.
+type TableCell struct {
+	Header []string   `json:"header"`
+	Rows   [][]string `json:"rows"`
+
+	formats map[string]string
+}
+
+// Returns "TableCell". Used for marshaling.
+func (c *TableCell) Type() string {
+	return "TableCell"
+}
+
+type tableCellOption func(*TableCell)
+
+// WithColumnFormat is an option for [Devcard.Table]. It sets the format (as in
+// [fmt.Sprintf]) for the values in the column.
+//
+// The option affects the rows appended after it.
+func WithColumnFormat(column, format string) tableCellOption {
+	return func(c *TableCell) {
+		if c.formats == nil {
+			c.formats = map[string]string{}
+		}
+		c.formats[column] = format
+	}
+}
+
+// Append appends rows to the table. Each of vals can be one of the following:
+//
+//   - A slice of structs (or pointers to structs). Each struct becomes a row.
+//     Exported fields become columns named after the fields. A column can be
+//     renamed with `devcard:"name"` tag; a field with `devcard:"-"` tag is
+//     skipped.
+//   - A slice of maps. Each map becomes a row; its keys become columns.
+//   - A slice of slices, such as [][]string. Each slice becomes a row, and
+//     its values are placed into columns by their position. When the table has
+//     no header yet, the first row becomes the header.
+//   - A single struct, map, or slice, which is treated as a single row.
+//   - An option, such as [WithColumnFormat].
+//
+// Columns that weren't present in the table before are appended to its right
+// side.
+func (c *TableCell) Append(vals ...any) {
+	for _, val := range vals {
+		if opt, ok := val.(tableCellOption); ok {
+			opt(c)
+			continue
+		}
+
+		v := reflect.ValueOf(val)
+		if isTableRows(v) {
+			for i := 0; i < v.Len(); i++ {
+				c.appendRow(v.Index(i))
+			}
+		} else {
+			c.appendRow(v)
+		}
+	}
+}
+
+// Erase clears the content of the cell.
+func (c *TableCell) Erase() {
+	c.Header = []string{}
+	c.Rows = [][]string{}
+}
+
+// NewTableCell creates [TableCell].
+func NewTableCell(vals ...any) *TableCell {
+	c := &TableCell{Header: []string{}, Rows: [][]string{}}
+	c.Append(vals...)
+	return c
+}
+
+// isTableRows reports whether v is a collection of rows, as opposed to a
+// single row.
+func isTableRows(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		// A byte slice is a string in disguise.
+		return false
+	}
+	kind := indirectType(v.Type().Elem()).Kind()
+	if kind == reflect.Interface {
+		// A slice such as []any is a collection of rows only if its elements
+		// are rows themselves; otherwise, it's a single row of values.
+		if v.Len() == 0 {
+			return true
+		}
+		elem := indirect(v.Index(0))
+		if elem.Kind() == reflect.Slice && elem.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		kind = elem.Kind()
+	}
+	switch kind {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func (c *TableCell) appendRow(v reflect.Value) {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		var columns []string
+		var values []any
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			name, ok := tableColumnName(field)
+			if !ok {
+				continue
+			}
+			var value any
+			if fv, err := v.FieldByIndexErr(field.Index); err == nil {
+				value = fv.Interface()
+			}
+			columns = append(columns, name)
+			values = append(values, value)
+		}
+		c.appendNamedRow(columns, values)
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(valToString(a.Interface()), valToString(b.Interface()))
+		})
+		columns := make([]string, len(keys))
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			columns[i] = valToString(key.Interface())
+			values[i] = v.MapIndex(key).Interface()
+		}
+		c.appendNamedRow(columns, values)
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			c.appendPositionalRow([]any{v.Interface()})
+			return
+		}
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Interface()
+		}
+		c.appendPositionalRow(values)
+
+	case reflect.Invalid:
+		c.appendPositionalRow([]any{nil})
+
+	default:
+		c.appendPositionalRow([]any{v.Interface()})
+	}
+}
+
+func tableColumnName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+	switch tag := field.Tag.Get("devcard"); tag {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return tag, true
+	}
+}
+
+func (c *TableCell) appendNamedRow(columns []string, values []any) {
+	for _, column := range columns {
+		if !slices.Contains(c.Header, column) {
+			c.addColumn(column)
+		}
+	}
+	row := make([]string, len(c.Header))
+	for i, column := range columns {
+		row[slices.Index(c.Header, column)] = c.format(column, values[i])
+	}
+	c.Rows = append(c.Rows, row)
+}
+
+func (c *TableCell) appendPositionalRow(values []any) {
+	if len(c.Header) == 0 && len(c.Rows) == 0 {
+		for _, v := range values {
+			c.Header = append(c.Header, valToString(v))
+		}
+		return
+	}
+
+	for len(c.Header) < len(values) {
+		c.addColumn("")
+	}
+	row := make([]string, len(c.Header))
+	for i, v := range values {
+		row[i] = c.format(c.Header[i], v)
+	}
+	c.Rows = append(c.Rows, row)
+}
+
+func (c *TableCell) addColumn(column string) {
+	c.Header = append(c.Header, column)
+	for i := range c.Rows {
+		c.Rows[i] = append(c.Rows[i], "")
+	}
+}
+
+func (c *TableCell) format(column string, val any) string {
+	if format, ok := c.formats[column]; ok && column != "" {
+		return fmt.Sprintf(format, val)
+	}
+	if val == nil {
+		return ""
+	}
+	return valToString(val)
+}
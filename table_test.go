@@ -0,0 +1,46 @@
+package devcard
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableAnySlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		vals   []any
+		header []string
+		rows   [][]string
+	}{
+		{
+			name:   "a single row of ints",
+			vals:   []any{[]int{1, 2, 3}},
+			header: []string{"1", "2", "3"},
+			rows:   [][]string{},
+		},
+		{
+			name:   "a single row of any",
+			vals:   []any{[]any{1, 2, 3}},
+			header: []string{"1", "2", "3"},
+			rows:   [][]string{},
+		},
+		{
+			name:   "a row after the header",
+			vals:   []any{[]string{"name", "age"}, []any{"Bob", 3}},
+			header: []string{"name", "age"},
+			rows:   [][]string{{"Bob", "3"}},
+		},
+		{
+			name:   "rows",
+			vals:   []any{[]any{[]any{"name", "age"}, []any{"Bob", 3}}},
+			header: []string{"name", "age"},
+			rows:   [][]string{{"Bob", "3"}},
+		},
+	}
+	for _, test := range tests {
+		c := NewTableCell(test.vals...)
+		if !reflect.DeepEqual(c.Header, test.header) || !reflect.DeepEqual(c.Rows, test.rows) {
+			t.Errorf("%s: got %q %q, want %q %q", test.name, c.Header, c.Rows, test.header, test.rows)
+		}
+	}
+}
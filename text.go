@@ -2,7 +2,11 @@ package devcard
 
 import (
 	"fmt"
+	"io"
+	"reflect"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sanity-io/litter"
 )
@@ -28,8 +32,216 @@ func valsToString(vals []any) string {
 	return s.String()
 }
 
-func pprint(val any) string {
+// Limits restrict the size of the pretty-printed values, so that huge values
+// don't choke the devcard's page. A negative or zero value means no limit,
+// except in [WithLimit], where zero means the value of [DefaultLimits].
+type Limits struct {
+	// MaxElements is the maximum number of printed elements of a slice, an
+	// array, or a map.
+	MaxElements int
+
+	// MaxDepth is the maximum nesting depth of printed values.
+	MaxDepth int
+
+	// MaxBytes is the maximum size of a printed value.
+	MaxBytes int
+}
+
+// DefaultLimits are the limits applied to the values of [ValueCell] and
+// [AnnotatedValueCell], unless [WithLimit] is used.
+var DefaultLimits = Limits{
+	MaxElements: 100,
+	MaxDepth:    10,
+	MaxBytes:    1 << 20,
+}
+
+type limitOption Limits
+
+// WithLimit is an option for [Devcard.Val] and [Devcard.Ann]. It overrides
+// [DefaultLimits] for the values of the cell. The zero fields of limits keep
+// their default values; use a negative value to remove a limit.
+func WithLimit(limits Limits) limitOption {
+	return limitOption(limits)
+}
+
+// splitLimit removes [WithLimit] options from vals, and returns the limits.
+func splitLimit(vals []any) ([]any, Limits) {
+	limits := DefaultLimits
+	result := make([]any, 0, len(vals))
+	for _, val := range vals {
+		if opt, ok := val.(limitOption); ok {
+			limits = Limits(opt).withDefaults()
+		} else {
+			result = append(result, val)
+		}
+	}
+	return result, limits
+}
+
+// withDefaults replaces the zero fields of the limits with the values of
+// [DefaultLimits].
+func (l Limits) withDefaults() Limits {
+	if l.MaxElements == 0 {
+		l.MaxElements = DefaultLimits.MaxElements
+	}
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultLimits.MaxDepth
+	}
+	if l.MaxBytes == 0 {
+		l.MaxBytes = DefaultLimits.MaxBytes
+	}
+	return l
+}
+
+// pprint pretty-prints the value. The omitted parts of the value are marked
+// with "… N more" comments.
+func pprint(val any, limits Limits) string {
 	cfg := litter.Config
 	cfg.HidePrivateFields = false
-	return cfg.Sdump(val)
+	if limits.MaxElements > 0 {
+		d := &elementsDumper{limits: limits, expanding: map[dumpKey]bool{}}
+		cfg.DumpFunc = d.dump
+		d.cfg = cfg
+	}
+	s := cfg.Sdump(val)
+	if limits.MaxDepth > 0 {
+		s = truncateDepth(s, limits.MaxDepth)
+	}
+	if limits.MaxBytes > 0 {
+		s = truncateBytes(s, limits.MaxBytes)
+	}
+	return s
+}
+
+// elementsDumper provides litter's DumpFunc that prints only the first
+// MaxElements elements of slices, arrays, and maps.
+//
+// The elements are printed by separate calls to litter, so litter can't
+// detect the cycles passing through them. To stop the cycles, the dumper keeps
+// track of the slices and maps whose elements are being printed, and of the
+// depth of the nested calls. It also stops printing the elements once their
+// total size exceeds MaxBytes.
+type elementsDumper struct {
+	cfg    litter.Options
+	limits Limits
+
+	expanding map[dumpKey]bool
+	depth     int
+	size      int // the total size of the printed elements
+}
+
+// dumpKey identifies a slice, an array, or a map whose elements are being
+// printed.
+type dumpKey struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+func (d *elementsDumper) dump(v reflect.Value, w io.Writer) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return false
+	}
+	// The elements can't be printed separately if they're accessed
+	// through an unexported field.
+	if v.Len() <= d.limits.MaxElements || !v.CanInterface() {
+		return false
+	}
+
+	key, addressable := dumpKey{typ: v.Type(), len: v.Len()}, true
+	switch {
+	case v.Kind() != reflect.Array:
+		key.ptr = v.Pointer()
+	case v.CanAddr():
+		key.ptr = v.UnsafeAddr()
+	default:
+		addressable = false
+	}
+	if (addressable && d.expanding[key]) || (d.limits.MaxDepth > 0 && d.depth >= d.limits.MaxDepth) {
+		io.WriteString(w, "{\n  // …\n}")
+		return true
+	}
+	if addressable {
+		d.expanding[key] = true
+		defer delete(d.expanding, key)
+	}
+	d.depth++
+	defer func() { d.depth-- }()
+
+	printed := 0
+	writeElement := func(s string) {
+		d.size += len(s)
+		printed++
+		io.WriteString(w, "  "+strings.ReplaceAll(s, "\n", "\n  ")+",\n")
+	}
+	full := func() bool {
+		return d.limits.MaxBytes > 0 && d.size >= d.limits.MaxBytes
+	}
+	io.WriteString(w, "{\n")
+	n := d.limits.MaxElements
+	if v.Kind() == reflect.Map {
+		keys := v.MapKeys()
+		slices.SortFunc(keys, compareKeys)
+		for _, k := range keys[:n] {
+			if full() {
+				break
+			}
+			writeElement(d.cfg.Sdump(k.Interface()) + ": " + d.cfg.Sdump(v.MapIndex(k).Interface()))
+		}
+	} else {
+		for i := range n {
+			if full() {
+				break
+			}
+			writeElement(d.cfg.Sdump(v.Index(i).Interface()))
+		}
+	}
+	fmt.Fprintf(w, "  // … %d more\n}", v.Len()-printed)
+	return true
+}
+
+// truncateDepth replaces the lines of the pretty-printed value that are
+// nested deeper than maxDepth with a "…" comment.
+func truncateDepth(s string, maxDepth int) string {
+	indent := strings.Repeat("  ", maxDepth+1)
+	var result []string
+	elided := false
+	for _, line := range strings.Split(s, "\n") {
+		switch {
+		case !strings.HasPrefix(line, indent):
+			result = append(result, line)
+			elided = false
+		case !elided:
+			result = append(result, indent+"// …")
+			elided = true
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// truncateBytes cuts the pretty-printed value to at most maxBytes, at the end
+// of a line.
+func truncateBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:maxBytes], '\n')
+	if cut < 0 {
+		cut = len(cutString(s, maxBytes))
+	}
+	return s[:cut] + fmt.Sprintf("\n// … %d more bytes", len(s)-cut)
+}
+
+// cutString returns the longest prefix of s that's at most n bytes long and
+// doesn't split a UTF-8 sequence.
+func cutString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
 }
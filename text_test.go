@@ -0,0 +1,148 @@
+package devcard
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPprintElements(t *testing.T) {
+	vals := make([]int, 150)
+	for i := range vals {
+		vals[i] = i
+	}
+	s := pprint(vals, Limits{MaxElements: 100})
+	if !strings.Contains(s, "  99,\n") || strings.Contains(s, "  100,\n") {
+		t.Errorf("expected the first 100 elements, got:\n%s", s)
+	}
+	if !strings.Contains(s, "// … 50 more") {
+		t.Errorf("expected the number of omitted elements, got:\n%s", s)
+	}
+
+	m := map[int]string{}
+	for i := range 5 {
+		m[i] = "x"
+	}
+	s = pprint(m, Limits{MaxElements: 3})
+	if !strings.Contains(s, "2: \"x\"") || strings.Contains(s, "3: \"x\"") || !strings.Contains(s, "// … 2 more") {
+		t.Errorf("expected the first 3 elements of the map, got:\n%s", s)
+	}
+}
+
+type cyclicNode struct {
+	Kids []*cyclicNode
+}
+
+func TestPprintCycle(t *testing.T) {
+	// The slice is long enough to be printed by elementsDumper, and each of
+	// its elements leads back to it.
+	root := &cyclicNode{}
+	for range 101 {
+		root.Kids = append(root.Kids, root)
+	}
+	s := pprint(root, DefaultLimits)
+	if !strings.Contains(s, "// … 1 more") {
+		t.Errorf("expected the omitted elements to be marked, got:\n%s", s)
+	}
+	if len(s) > DefaultLimits.MaxBytes {
+		t.Errorf("the output exceeds the limit: %d bytes", len(s))
+	}
+}
+
+type listNode struct {
+	Next *listNode
+}
+
+func TestValueTreeCycle(t *testing.T) {
+	n := &listNode{}
+	n.Next = n
+	// The depth isn't limited, so only the cycle detection stops the tree.
+	tree := NewValueTree(n, Limits{MaxElements: 1000})
+	next := tree.Children[0]
+	if !next.Truncated || len(next.Children) != 0 {
+		t.Errorf("expected the cyclic reference to be truncated, got %+v", next)
+	}
+
+	root := &cyclicNode{}
+	root.Kids = append(root.Kids, root, root)
+	tree = NewValueTree(root, Limits{})
+	kids := tree.Children[0]
+	if len(kids.Children) != 2 || !kids.Children[0].Truncated || !kids.Children[1].Truncated {
+		t.Errorf("expected the cyclic references to be truncated, got %+v", kids)
+	}
+}
+
+func TestPprintBytes(t *testing.T) {
+	// Each element is large, so the elements stop being printed once their
+	// total size exceeds the limit.
+	vals := make([][]int, 200)
+	for i := range vals {
+		vals[i] = make([]int, 50)
+	}
+	s := pprint(vals, Limits{MaxElements: 100, MaxBytes: 2000})
+	if len(s) > 2100 {
+		t.Errorf("the output exceeds the limit: %d bytes", len(s))
+	}
+	if !strings.Contains(s, "more") {
+		t.Errorf("expected the omitted part to be marked, got:\n%s", s)
+	}
+}
+
+func TestTruncateDepth(t *testing.T) {
+	s := strings.Join([]string{
+		"a{",
+		"  b{",
+		"    c,",
+		"    d,",
+		"  },",
+		"  e,",
+		"}",
+	}, "\n")
+	want := strings.Join([]string{
+		"a{",
+		"  b{",
+		"    // …",
+		"  },",
+		"  e,",
+		"}",
+	}, "\n")
+	if got := truncateDepth(s, 1); got != want {
+		t.Errorf("truncateDepth:\n%s\nwant:\n%s", got, want)
+	}
+	if got := truncateDepth(s, 2); got != s {
+		t.Errorf("truncateDepth changed a shallow value:\n%s", got)
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		s        string
+		maxBytes int
+		want     string
+	}{
+		{"abc\ndef", 10, "abc\ndef"},
+		{"abc\ndef\nghi", 9, "abc\ndef\n// … 4 more bytes"},
+		{"abcdef", 4, "abcd\n// … 2 more bytes"},
+		{"ab€", 3, "ab\n// … 3 more bytes"},
+	}
+	for _, test := range tests {
+		got := truncateBytes(test.s, test.maxBytes)
+		if got != test.want {
+			t.Errorf("truncateBytes(%q, %d) = %q, want %q", test.s, test.maxBytes, got, test.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateBytes(%q, %d) = %q: invalid UTF-8", test.s, test.maxBytes, got)
+		}
+	}
+}
+
+func TestSplitLimit(t *testing.T) {
+	vals, limits := splitLimit([]any{1, WithLimit(Limits{MaxElements: 1000, MaxBytes: -1}), 2})
+	if len(vals) != 2 {
+		t.Errorf("expected the option to be removed, got %v", vals)
+	}
+	want := Limits{MaxElements: 1000, MaxDepth: DefaultLimits.MaxDepth, MaxBytes: -1}
+	if limits != want {
+		t.Errorf("got %+v, want %+v", limits, want)
+	}
+}
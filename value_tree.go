@@ -0,0 +1,248 @@
+package devcard
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// ValueTree is a structured representation of a Go value. It's rendered as a
+// collapsible tree.
+type ValueTree struct {
+	// Key is the name of the struct's field, the index of the slice's
+	// element, or the map's key. It's empty for the root of the tree.
+	Key string `json:"key,omitempty"`
+
+	// Type is the type of the value, such as "[]int" or "*main.Foo".
+	Type string `json:"type"`
+
+	// Kind is the kind of the value (see [reflect.Kind]), such as "slice" or
+	// "struct".
+	Kind string `json:"kind"`
+
+	// Text is the value of a scalar, such as `42` or `"foo"`, or "nil".
+	Text string `json:"text,omitempty"`
+
+	// Len is the length of a slice, an array, or a map.
+	Len int `json:"len,omitempty"`
+
+	// Children are the struct's fields, or the elements of a slice, an array,
+	// or a map.
+	Children []ValueTree `json:"children,omitempty"`
+
+	// Truncated is true if the children are omitted due to the depth limit,
+	// or because the value is a cyclic reference to its ancestor.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// More is the number of the children omitted due to the limits.
+	More int `json:"more,omitempty"`
+}
+
+// NewValueTree creates the [ValueTree] of val, truncated according to the
+// limits. The size of the tree is estimated roughly, as the size of its
+// rendered HTML.
+func NewValueTree(val any, limits Limits) ValueTree {
+	b := &treeBuilder{limits: limits, expanding: map[dumpKey]bool{}}
+	return b.build(reflect.ValueOf(val), 0)
+}
+
+type treeBuilder struct {
+	limits Limits
+	size   int // the estimated size of the tree built so far
+
+	// expanding are the pointers, slices, and maps whose children are being
+	// built; revisiting one of them means a cycle.
+	expanding map[dumpKey]bool
+}
+
+// nodeSize is the estimated size of the markup of a tree's rendered node,
+// excluding its type and text.
+const nodeSize = 160
+
+// full reports whether the tree has reached its size limit.
+func (b *treeBuilder) full() bool {
+	return b.limits.MaxBytes > 0 && b.size >= b.limits.MaxBytes
+}
+
+// expand marks the value's children as being built. It returns false if
+// they're already being built, i.e. the value references its ancestor.
+func (b *treeBuilder) expand(v reflect.Value) bool {
+	key := treeKey(v)
+	if b.expanding[key] {
+		return false
+	}
+	b.expanding[key] = true
+	return true
+}
+
+// done unmarks the value marked by expand.
+func (b *treeBuilder) done(v reflect.Value) {
+	delete(b.expanding, treeKey(v))
+}
+
+// treeKey identifies a pointer, a slice, or a map.
+func treeKey(v reflect.Value) dumpKey {
+	key := dumpKey{typ: v.Type(), ptr: v.Pointer()}
+	if v.Kind() != reflect.Pointer {
+		key.len = v.Len()
+	}
+	return key
+}
+
+// deep reports whether the children of the value at depth must be omitted.
+func (b *treeBuilder) deep(depth int) bool {
+	return b.limits.MaxDepth > 0 && depth >= b.limits.MaxDepth
+}
+
+// elements returns the number of the value's elements to be included.
+func (b *treeBuilder) elements(n int) int {
+	if b.limits.MaxElements > 0 {
+		return min(n, b.limits.MaxElements)
+	}
+	return n
+}
+
+func (b *treeBuilder) build(v reflect.Value, depth int) ValueTree {
+	if !v.IsValid() {
+		return ValueTree{Type: "nil", Kind: "invalid", Text: "nil"}
+	}
+
+	t := ValueTree{Type: v.Type().String(), Kind: v.Kind().String()}
+	b.size += nodeSize + len(t.Type)
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			t.Text = "nil"
+			return t
+		}
+		if b.deep(depth) || !b.expand(v) {
+			t.Truncated = true
+			return t
+		}
+		defer b.done(v)
+		elem := b.build(v.Elem(), depth+1)
+		elem.Type = t.Type
+		return elem
+
+	case reflect.Interface:
+		if v.IsNil() {
+			t.Text = "nil"
+			return t
+		}
+		return b.build(v.Elem(), depth)
+
+	case reflect.Struct:
+		if v.NumField() == 0 {
+			return t
+		}
+		if b.deep(depth) {
+			t.Truncated = true
+			return t
+		}
+		for i := range v.NumField() {
+			if b.full() {
+				t.More = v.NumField() - i
+				break
+			}
+			child := b.build(v.Field(i), depth+1)
+			child.Key = v.Type().Field(i).Name
+			t.Children = append(t.Children, child)
+		}
+
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if v.Kind() != reflect.Array && v.IsNil() {
+			t.Text = "nil"
+			return t
+		}
+		t.Len = v.Len()
+		if t.Len == 0 {
+			return t
+		}
+		if b.deep(depth) {
+			t.Truncated = true
+			return t
+		}
+		if v.Kind() != reflect.Array {
+			if !b.expand(v) {
+				t.Truncated = true
+				return t
+			}
+			defer b.done(v)
+		}
+		n := b.elements(t.Len)
+		if v.Kind() == reflect.Map {
+			t.Children = b.mapChildren(v, n, depth)
+		} else {
+			for i := range n {
+				if b.full() {
+					break
+				}
+				child := b.build(v.Index(i), depth+1)
+				child.Key = strconv.Itoa(i)
+				t.Children = append(t.Children, child)
+			}
+		}
+		t.More = t.Len - len(t.Children)
+
+	case reflect.String:
+		t.Text = strconv.Quote(v.String())
+		if b.limits.MaxBytes > 0 && len(t.Text) > b.limits.MaxBytes {
+			t.Text = cutString(t.Text, b.limits.MaxBytes) + "…"
+		}
+		b.size += len(t.Text)
+
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			t.Text = "nil"
+		} else {
+			t.Text = fmt.Sprintf("%#x", v.Pointer())
+		}
+
+	default:
+		t.Text = fmt.Sprint(v)
+	}
+	return t
+}
+
+// mapChildren returns the first n of the map's elements, sorted by their
+// keys.
+func (b *treeBuilder) mapChildren(v reflect.Value, n, depth int) []ValueTree {
+	keys := v.MapKeys()
+	slices.SortFunc(keys, compareKeys)
+	var children []ValueTree
+	for _, k := range keys[:n] {
+		if b.full() {
+			break
+		}
+		child := b.build(v.MapIndex(k), depth+1)
+		child.Key = mapKey(k)
+		children = append(children, child)
+	}
+	return children
+}
+
+func compareKeys(a, b reflect.Value) int {
+	if a.Kind() == b.Kind() {
+		switch {
+		case a.CanInt():
+			return cmp.Compare(a.Int(), b.Int())
+		case a.CanUint():
+			return cmp.Compare(a.Uint(), b.Uint())
+		case a.CanFloat():
+			return cmp.Compare(a.Float(), b.Float())
+		}
+	}
+	return cmp.Compare(mapKey(a), mapKey(b))
+}
+
+func mapKey(k reflect.Value) string {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+	if k.Kind() == reflect.String {
+		return strconv.Quote(k.String())
+	}
+	return fmt.Sprint(k)
+}